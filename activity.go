@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// HexActivity tracks how often a hex's flower_count has actually changed between checks in
+// --watch mode, so rapidly changing hexes get re-synced more often than dormant ones instead of
+// every hex paying the same fixed --watch-hex-interval cost.
+type HexActivity struct {
+	HexID        string `gorm:"primaryKey"`
+	IntervalSecs int
+	CheckedAt    time.Time
+}
+
+var tableNameHexActivity = "pollen_hex_activity"
+
+func (h *HexActivity) TableName() string {
+	return tableNameHexActivity
+}
+
+func init() {
+	models = append(models, HexActivity{})
+}
+
+// activityBackoffFactor is how much a hex's adaptive interval grows each time it's checked with
+// no change. recordActivity bounds the result: never faster than --watch-hex-interval, never
+// slower than --watch-full-interval.
+const activityBackoffFactor = 2
+
+// activityDue reports whether hexID's adaptive interval has elapsed since it was last checked,
+// defaulting to true (due) for a hex with no recorded activity yet.
+func activityDue(db *gorm.DB, hexID string) (bool, error) {
+	var activity HexActivity
+	err := db.Where("hex_id = ?", hexID).Take(&activity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return time.Since(activity.CheckedAt) >= time.Duration(activity.IntervalSecs)*time.Second, nil
+}
+
+// recordActivity upserts hexID's activity record: on a real change, the interval resets to the
+// floor (--watch-hex-interval); on no change, it backs off exponentially up to the ceiling
+// (--watch-full-interval).
+func recordActivity(db *gorm.DB, hexID string, changed bool) error {
+	var previous HexActivity
+	err := db.Where("hex_id = ?", hexID).Take(&previous).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	floor := int(watchHexInterval.Seconds())
+	ceiling := int(watchFullInterval.Seconds())
+	interval := floor
+	if !changed {
+		interval = previous.IntervalSecs * activityBackoffFactor
+		if interval < floor {
+			interval = floor
+		}
+		if interval > ceiling {
+			interval = ceiling
+		}
+	}
+
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "hex_id"}},
+		UpdateAll: true,
+	}).Create(&HexActivity{HexID: hexID, IntervalSecs: interval, CheckedAt: time.Now()}).Error
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// latencyStats accumulates a running total/count (for an average), a max, and an error count for
+// one Pollen API endpoint. A running total is cheap to update on every call without needing a
+// real histogram library, and is enough to tell "the API got slower" or "the API started
+// erroring" apart from "our network is having a bad day".
+type latencyStats struct {
+	mu      sync.Mutex
+	count   int64
+	errors  int64
+	totalMs int64
+	maxMs   int64
+}
+
+// apiLatency holds one latencyStats per endpoint recordAPICall already tracks call counts for.
+var apiLatency = map[string]*latencyStats{
+	"pollen_hexes":   {},
+	"pollen_hex":     {},
+	"pollen_flowers": {},
+	"pollen_rewards": {},
+}
+
+// recordAPILatency records one call's duration (in milliseconds) and outcome against the
+// endpoint whose URL prefix matches url, mirroring recordAPICall's endpoint matching.
+func recordAPILatency(url string, ms int64, failed bool) {
+	var stats *latencyStats
+	switch {
+	case strings.HasPrefix(url, pollenAPIHexes):
+		stats = apiLatency["pollen_hexes"]
+	case strings.HasPrefix(url, pollenAPIHex):
+		stats = apiLatency["pollen_hex"]
+	case strings.HasPrefix(url, pollenAPIFlowers):
+		stats = apiLatency["pollen_flowers"]
+	case strings.HasPrefix(url, pollenAPIRewards):
+		stats = apiLatency["pollen_rewards"]
+	default:
+		return
+	}
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	stats.count++
+	stats.totalMs += ms
+	if ms > stats.maxMs {
+		stats.maxMs = ms
+	}
+	if failed {
+		stats.errors++
+	}
+}
+
+// snapshot returns call count, average latency, max latency (both ms), and error rate, read
+// under lock so a concurrent recordAPILatency (the reward worker pool, the hex pipeline) never
+// sees a partial update.
+func (s *latencyStats) snapshot() (count, avgMs, maxMs int64, errorRate float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.count == 0 {
+		return 0, 0, 0, 0
+	}
+	return s.count, s.totalMs / s.count, s.maxMs, float64(s.errors) / float64(s.count)
+}
+
+// apiLatencySummary renders each endpoint that saw at least one call as "name(avg=..ms,max=..ms,
+// errors=..%)", appended to the run summary line alongside metricsSummary.
+func apiLatencySummary() string {
+	names := make([]string, 0, len(apiLatency))
+	for name := range apiLatency {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		count, avgMs, maxMs, errRate := apiLatency[name].snapshot()
+		if count == 0 {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s(avg=%dms,max=%dms,errors=%.1f%%)", name, avgMs, maxMs, errRate*100))
+	}
+	return strings.Join(parts, " ")
+}
+
+// writeAPILatencyMetrics appends the latency/error gauges to the /metrics response, called from
+// serveMetrics's handler alongside its own counters.
+func writeAPILatencyMetrics(w http.ResponseWriter) {
+	for name, stats := range apiLatency {
+		count, avgMs, maxMs, errRate := stats.snapshot()
+		fmt.Fprintf(w, "pollen_api_latency_avg_ms{endpoint=%q} %d\n", name, avgMs)
+		fmt.Fprintf(w, "pollen_api_latency_max_ms{endpoint=%q} %d\n", name, maxMs)
+		fmt.Fprintf(w, "pollen_api_error_rate{endpoint=%q} %f\n", name, errRate)
+		fmt.Fprintf(w, "pollen_api_calls_sampled{endpoint=%q} %d\n", name, count)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func init() {
+	registerSubcommand("backfill", runBackfillCommand)
+	models = append(models, BackfillCheckpoint{})
+}
+
+// BackfillCheckpoint marks a flower as having had its full reward history fetched by `backfill`.
+// Unlike RewardCheckpoint, which steady-state sync clears at the end of every sweep so rewards
+// keep accruing daily, a backfill checkpoint is permanent: once a flower's full history is in,
+// there's nothing more for backfill to do for it, ever. --reset is the only way to clear one.
+type BackfillCheckpoint struct {
+	FlowerID    string `gorm:"primaryKey"`
+	CompletedAt time.Time
+}
+
+var tableNameBackfillCheckpoint = "pollen_backfill_checkpoints"
+
+func (c *BackfillCheckpoint) TableName() string {
+	return tableNameBackfillCheckpoint
+}
+
+// isBackfillCheckpointed reports whether flowerID's full reward history has already been
+// fetched by a previous backfill run.
+func isBackfillCheckpointed(db *gorm.DB, flowerID string) (bool, error) {
+	var count int64
+	err := db.Model(&BackfillCheckpoint{}).Where("flower_id = ?", flowerID).Count(&count).Error
+	return count > 0, err
+}
+
+// markBackfillCheckpointed records flowerID as fully backfilled.
+func markBackfillCheckpointed(db *gorm.DB, flowerID string) error {
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&BackfillCheckpoint{
+		FlowerID:    flowerID,
+		CompletedAt: time.Now(),
+	}).Error
+}
+
+// runBackfillCommand walks every flower's full reward history, deliberately separate from the
+// fast steady-state `sync` command: it ignores --rewards-since/--rewards-until/--since (the
+// whole point is getting everything), checkpoints aggressively per flower so --max-duration or
+// --max-requests can split an initial load across many nights, and never auto-clears its
+// checkpoints, so rerunning backfill after a budget-limited exit resumes exactly where it left
+// off instead of restarting.
+func runBackfillCommand(args []string) int {
+	fs := flag.NewFlagSet("backfill", flag.ExitOnError)
+	reset := fs.Bool("reset", false, "clear backfill checkpoints first and refetch every flower's full history from scratch")
+	fs.Parse(args)
+
+	db, err := openDB(os.Getenv("PG_URL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db connect failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	if *reset {
+		if err := db.Exec("DELETE FROM " + tableNameBackfillCheckpoint).Error; err != nil {
+			fmt.Fprintf(os.Stderr, "resetting backfill checkpoints failed: %v\n", err)
+			return ExitDBFailure
+		}
+	}
+
+	var flowerNames []string
+	if err := db.Table(tableNameFlower).Select("id").Find(&flowerNames).Error; err != nil {
+		fmt.Fprintf(os.Stderr, "query failed: %v\n", err)
+		return ExitDBFailure
+	}
+	logProgress("Backfilling full reward history for %d flowers\n", len(flowerNames))
+
+	for i, flowerName := range flowerNames {
+		if deadlineExceeded() {
+			logProgress("--max-duration reached, backfill checkpointed at %d/%d; rerun backfill to resume\n", i, len(flowerNames))
+			return ExitPartialSuccess
+		}
+		if requestBudgetExceeded() {
+			logProgress("--max-requests reached, backfill checkpointed at %d/%d; rerun backfill to resume\n", i, len(flowerNames))
+			return ExitPartialSuccess
+		}
+		if i%100 == 0 {
+			logProgress("Backfill progress: %d/%d\n", i, len(flowerNames))
+		}
+
+		done, err := isBackfillCheckpointed(db, flowerName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "checkpoint lookup failed: %v\n", err)
+			return ExitDBFailure
+		}
+		if done {
+			continue
+		}
+
+		if err := fetchAndStoreRewards(db, flowerName, false); err != nil && !errors.Is(err, errRewardDeadLettered) {
+			fmt.Fprintf(os.Stderr, "backfilling %s failed: %v\n", flowerName, err)
+			return ExitRunFailure
+		}
+		if err := markBackfillCheckpointed(db, flowerName); err != nil {
+			fmt.Fprintf(os.Stderr, "marking backfill checkpoint failed: %v\n", err)
+			return ExitDBFailure
+		}
+	}
+
+	logProgress("backfill complete: every flower's full reward history has been fetched\n")
+	return ExitSuccess
+}
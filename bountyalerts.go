@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Bounty alerts let an operator get pinged the moment a bounty worth deploying hardware for
+// appears, or before one they're already covering expires, rather than having to poll reports.
+var (
+	alertWebhook       = flag.String("alert-webhook", "", "webhook URL (Slack-compatible {\"text\":...} payload) to POST bounty alerts to; empty disables alerting")
+	alertRegions       = flag.String("alert-regions", "", "comma-separated H3HexTop regions to alert on; empty alerts on every region")
+	bountyExpiryWindow = flag.Duration("bounty-expiry-window", time.Hour, "how far ahead of bounty_time to send an expiring-bounty alert")
+)
+
+// BountyAlertSent records that an expiring-bounty alert was already sent for a hex's current
+// bounty, so checkExpiringBountyAlerts doesn't re-alert on every subsequent run while the same
+// bounty is still active.
+type BountyAlertSent struct {
+	HexID      string `gorm:"primaryKey"`
+	BountyTime string `gorm:"primaryKey"`
+	SentAt     time.Time
+}
+
+var tableNameBountyAlertSent = "pollen_bounty_alerts_sent"
+
+func (a *BountyAlertSent) TableName() string {
+	return tableNameBountyAlertSent
+}
+
+func init() {
+	models = append(models, BountyAlertSent{})
+}
+
+// alertRegionMatches reports whether region should be alerted on given --alert-regions.
+func alertRegionMatches(region string) bool {
+	if *alertRegions == "" {
+		return true
+	}
+	for _, r := range splitCommaList(*alertRegions) {
+		if r == region {
+			return true
+		}
+	}
+	return false
+}
+
+// sendAlert POSTs message to --alert-webhook as a Slack-compatible payload, a no-op if it isn't
+// set.
+func sendAlert(message string) error {
+	if *alertWebhook == "" {
+		return nil
+	}
+	payload, err := json.Marshal(struct {
+		Text string `json:"text"`
+	}{message})
+	if err != nil {
+		return err
+	}
+	res, err := http.Post(*alertWebhook, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("posting alert webhook: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("alert webhook returned status %d", res.StatusCode)
+	}
+	return nil
+}
+
+// alertNewBounty sends a new-bounty alert if bounty just appeared (previous was empty) on a hex
+// in a configured region.
+func alertNewBounty(hexID, region, previousBounty, bounty string) error {
+	if previousBounty != "" || bounty == "" || !alertRegionMatches(region) {
+		return nil
+	}
+	return sendAlert(fmt.Sprintf("New bounty %q on hex %s (region %s)", bounty, hexID, region))
+}
+
+// checkExpiringBountyAlerts alerts once per active bounty whose bounty_time falls within
+// --bounty-expiry-window, scoped to --alert-regions, recording each alert sent to
+// pollen_bounty_alerts_sent so it isn't repeated on the next run.
+func checkExpiringBountyAlerts(db *gorm.DB) error {
+	if *alertWebhook == "" {
+		return nil
+	}
+	var hexes []Hex
+	if err := db.Select("id", "bounty", "bounty_time", "h3_hex_top").
+		Where("bounty IS NOT NULL AND bounty <> ''").
+		Find(&hexes).Error; err != nil {
+		return err
+	}
+	deadline := time.Now().Add(*bountyExpiryWindow)
+	for _, hex := range hexes {
+		if !alertRegionMatches(hex.H3HexTop) {
+			continue
+		}
+		expiresAt := parseNullableTime(&hex.BountyTime)
+		if expiresAt == nil || expiresAt.After(deadline) {
+			continue
+		}
+		var already BountyAlertSent
+		err := db.Where("hex_id = ? AND bounty_time = ?", hex.ID, hex.BountyTime).Take(&already).Error
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err := sendAlert(fmt.Sprintf("Bounty %q on hex %s (region %s) expires at %s", hex.Bounty, hex.ID, hex.H3HexTop, hex.BountyTime)); err != nil {
+			return err
+		}
+		if err := db.Create(&BountyAlertSent{HexID: hex.ID, BountyTime: hex.BountyTime, SentAt: time.Now()}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"flag"
+	"sync/atomic"
+)
+
+// maxRequests caps the number of API calls (Pollen + Nominatim combined) a single run will make
+// before checkpointing and exiting cleanly, the same way --max-duration caps wall-clock time, so
+// a huge initial backfill can be spread across several nights without blowing through a rate
+// limit or a metered API plan.
+var maxRequests = flag.Int("max-requests", 0, "if > 0, checkpoint and exit once this many API calls have been made")
+
+// requestBudgetExceeded reports whether --max-requests has been reached, summing the same
+// per-endpoint counters the /metrics endpoint exposes rather than keeping a separate counter.
+func requestBudgetExceeded() bool {
+	if *maxRequests <= 0 {
+		return false
+	}
+	var total int64
+	for _, c := range metrics.apiCalls {
+		total += atomic.LoadInt64(c)
+	}
+	return total >= int64(*maxRequests)
+}
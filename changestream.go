@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+)
+
+// The change stream flags let a sync optionally publish every inserted/updated row to Kafka or
+// NATS as it's written, so streaming consumers see changes without needing CDC set up against
+// the Postgres instance itself. Both are optional and independent: set either, both, or neither.
+var (
+	changeStreamKafkaBrokers = flag.String("change-stream-kafka-brokers", "", "comma-separated Kafka broker addresses to publish row changes to; empty disables the Kafka sink")
+	changeStreamKafkaTopic   = flag.String("change-stream-kafka-topic", "pollen_changes", "Kafka topic row changes are published to")
+	changeStreamNATSURL      = flag.String("change-stream-nats-url", "", "NATS server URL to publish row changes to; empty disables the NATS sink")
+	changeStreamNATSSubject  = flag.String("change-stream-nats-subject", "pollen.changes", "NATS subject row changes are published to")
+)
+
+// activeChangeStream is the package-level sink every write path publishes through, set once in
+// runSync. It holds no sinks (and Publish/Close are safe no-ops) when no change-stream flags are
+// set, and is nil outside runSync.
+var activeChangeStream *changeStream
+
+// changeEvent is the JSON envelope published for every inserted/updated row.
+type changeEvent struct {
+	Table string      `json:"table"`
+	Op    string      `json:"op"`
+	Row   interface{} `json:"row"`
+}
+
+// changeStream publishes row changes to whichever sinks are configured. A nil *changeStream, or
+// one with no sinks (what openChangeStream returns when nothing is configured), is safe to call
+// Publish/Close on.
+type changeStream struct {
+	kafkaWriter *kafka.Writer
+	nc          *nats.Conn
+}
+
+// openChangeStream connects to whichever of Kafka/NATS are configured via flags. Either, both,
+// or neither may be set; an unconfigured sink is simply left nil on the returned changeStream.
+func openChangeStream() (*changeStream, error) {
+	cs := &changeStream{}
+	if *changeStreamKafkaBrokers != "" {
+		cs.kafkaWriter = &kafka.Writer{
+			Addr:     kafka.TCP(splitCommaList(*changeStreamKafkaBrokers)...),
+			Topic:    *changeStreamKafkaTopic,
+			Balancer: &kafka.LeastBytes{},
+		}
+	}
+	if *changeStreamNATSURL != "" {
+		nc, err := nats.Connect(*changeStreamNATSURL)
+		if err != nil {
+			return nil, fmt.Errorf("connecting to NATS: %w", err)
+		}
+		cs.nc = nc
+	}
+	return cs, nil
+}
+
+// Publish sends a changeEvent for one row to every configured sink. Errors from individual
+// sinks are returned wrapped rather than silently dropped, since a publish failure usually
+// means a misconfigured broker the operator needs to know about.
+func (cs *changeStream) Publish(table, op string, row interface{}) error {
+	if cs == nil || (cs.kafkaWriter == nil && cs.nc == nil) {
+		return nil
+	}
+	payload, err := json.Marshal(changeEvent{Table: table, Op: op, Row: row})
+	if err != nil {
+		return err
+	}
+	if cs.kafkaWriter != nil {
+		if err := cs.kafkaWriter.WriteMessages(context.Background(), kafka.Message{Value: payload}); err != nil {
+			return fmt.Errorf("publishing to Kafka: %w", err)
+		}
+	}
+	if cs.nc != nil {
+		if err := cs.nc.Publish(*changeStreamNATSSubject, payload); err != nil {
+			return fmt.Errorf("publishing to NATS: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close releases any open sink connections.
+func (cs *changeStream) Close() {
+	if cs == nil {
+		return
+	}
+	if cs.kafkaWriter != nil {
+		cs.kafkaWriter.Close()
+	}
+	if cs.nc != nil {
+		cs.nc.Close()
+	}
+}
+
+// splitCommaList splits a comma-separated flag value, trimming nothing extra since broker
+// addresses don't carry surrounding whitespace in practice.
+func splitCommaList(s string) []string {
+	out := []string{}
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == ',' {
+			out = append(out, s[start:i])
+			start = i + 1
+		}
+	}
+	out = append(out, s[start:])
+	return out
+}
@@ -0,0 +1,12 @@
+package main
+
+// subcommands maps a subcommand name (os.Args[1]) to its handler, which parses its own
+// flags from the remaining args and returns a process exit code. Falling back to runSync
+// when no name matches keeps `go run main.go <hexes...>` working unchanged.
+var subcommands = map[string]func(args []string) int{}
+
+// registerSubcommand adds a named subcommand. Intended to be called from each subcommand's
+// own file via init(), so new subcommands don't require touching this file.
+func registerSubcommand(name string, handler func(args []string) int) {
+	subcommands[name] = handler
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Client is a bee/SIM device identified by a reward record's `client` field, tracked apart from
+// Reward so "when did we first see this client, and which flowers has it used" doesn't require
+// scanning the whole rewards history.
+type Client struct {
+	ID        string `gorm:"primaryKey"`
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+var tableNameClient = "pollen_clients"
+
+func (c *Client) TableName() string {
+	return tableNameClient
+}
+
+// ClientFlower is one (client, flower) pairing observed in a reward record, the normalized form
+// of "which flowers served this client".
+type ClientFlower struct {
+	ClientID string `gorm:"primaryKey"`
+	FlowerID string `gorm:"primaryKey"`
+}
+
+var tableNameClientFlower = "pollen_client_flowers"
+
+func (c *ClientFlower) TableName() string {
+	return tableNameClientFlower
+}
+
+func init() {
+	models = append(models, Client{}, ClientFlower{})
+}
+
+// recordClients upserts a Client row (setting FirstSeen on first sight, bumping LastSeen
+// otherwise) and a ClientFlower pairing for every distinct client in rewardItems, keyed off the
+// flower whose reward payload they came from.
+func recordClients(tx *gorm.DB, flowerID string, rewardItems []DeviceRewardItem) error {
+	seen := map[string]bool{}
+	now := time.Now()
+	for _, r := range rewardItems {
+		if r.Client == "" || seen[r.Client] {
+			continue
+		}
+		seen[r.Client] = true
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: []clause.Assignment{{Column: clause.Column{Name: "last_seen"}, Value: now}},
+		}).Create(&Client{ID: r.Client, FirstSeen: now, LastSeen: now}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&ClientFlower{ClientID: r.Client, FlowerID: flowerID}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
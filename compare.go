@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func init() {
+	registerSubcommand("compare", runCompareCommand)
+}
+
+// runCompareCommand diffs a single entity (hexes or flowers) between two snapshots, each of
+// which is either a Postgres URL or a directory containing "<table>.csv" (e.g.
+// "pollen_flowers.csv" for --entity flowers), reporting rows added, removed, and changed by id.
+func runCompareCommand(args []string) int {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	a := fs.String("a", "", "first snapshot: a PG_URL or a directory containing <table>.csv (e.g. pollen_flowers.csv)")
+	b := fs.String("b", "", "second snapshot: a PG_URL or a directory containing <table>.csv (e.g. pollen_flowers.csv)")
+	entity := fs.String("entity", "flowers", "entity to compare: flowers or hexes")
+	fs.Parse(args)
+	if *a == "" || *b == "" {
+		fmt.Fprintln(os.Stderr, "usage: compare --a <snapshot> --b <snapshot> --entity flowers|hexes")
+		return ExitRunFailure
+	}
+	tableName, ok := importTables[*entity]
+	if !ok || *entity == "rewards" {
+		fmt.Fprintf(os.Stderr, "unsupported entity %q (expected flowers or hexes)\n", *entity)
+		return ExitRunFailure
+	}
+
+	rowsA, err := loadSnapshot(*a, tableName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load snapshot a: %v\n", err)
+		return ExitRunFailure
+	}
+	rowsB, err := loadSnapshot(*b, tableName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load snapshot b: %v\n", err)
+		return ExitRunFailure
+	}
+
+	var added, removed, changed []string
+	for id, rowB := range rowsB {
+		rowA, ok := rowsA[id]
+		if !ok {
+			added = append(added, id)
+			continue
+		}
+		if !rowsEqual(rowA, rowB) {
+			changed = append(changed, id)
+		}
+	}
+	for id := range rowsA {
+		if _, ok := rowsB[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+
+	fmt.Printf("%s: +%d added, -%d removed, ~%d changed\n", *entity, len(added), len(removed), len(changed))
+	for _, id := range added {
+		fmt.Printf("  + %s\n", id)
+	}
+	for _, id := range removed {
+		fmt.Printf("  - %s\n", id)
+	}
+	for _, id := range changed {
+		fmt.Printf("  ~ %s\n", id)
+	}
+	return ExitSuccess
+}
+
+// rowsEqual compares two column maps field by field, treating differing types (e.g. a CSV's
+// strings vs a DB driver's native types) as equal if their string forms match.
+func rowsEqual(a, b map[string]interface{}) bool {
+	for k, av := range a {
+		if fmt.Sprintf("%v", av) != fmt.Sprintf("%v", b[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+// loadSnapshot loads a table's rows, keyed by id, from either a Postgres URL or a directory
+// containing "<table>.csv".
+func loadSnapshot(source, tableName string) (map[string]map[string]interface{}, error) {
+	if info, err := os.Stat(source); err == nil && info.IsDir() {
+		return loadSnapshotCSV(filepath.Join(source, tableName+".csv"))
+	}
+	return loadSnapshotDB(source, tableName)
+}
+
+func loadSnapshotDB(pgURL, tableName string) (map[string]map[string]interface{}, error) {
+	db, err := gorm.Open(postgres.Open(pgURL), &gorm.Config{Logger: quietLogger()})
+	if err != nil {
+		return nil, err
+	}
+	var rows []map[string]interface{}
+	if err := db.Table(tableName).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return keyByID(rows)
+}
+
+func loadSnapshotCSV(path string) (map[string]map[string]interface{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return map[string]map[string]interface{}{}, nil
+	}
+	header := records[0]
+	rows := make([]map[string]interface{}, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]interface{}, len(header))
+		for i, col := range header {
+			if i < len(record) {
+				row[col] = record[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return keyByID(rows)
+}
+
+func keyByID(rows []map[string]interface{}) (map[string]map[string]interface{}, error) {
+	byID := make(map[string]map[string]interface{}, len(rows))
+	for _, row := range rows {
+		id, ok := row["id"]
+		if !ok {
+			return nil, fmt.Errorf("row missing id column")
+		}
+		byID[strings.TrimSpace(fmt.Sprintf("%v", id))] = row
+	}
+	return byID, nil
+}
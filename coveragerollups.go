@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CoverageRollup aggregates hex coverage, flower counts, and reward earnings at a geocoded
+// granularity (city, county, or state), the level most reports are actually written at, so
+// callers don't need to re-derive them from pollen_hexes/pollen_reward_coverage every time.
+// Refreshed wholesale after each sync (see refreshCoverageRollups), not a materialized view,
+// since it rolls up three tables rather than being a straightforward GROUP BY over one.
+type CoverageRollup struct {
+	Geography       string `gorm:"primaryKey"` // "city", "county", or "state"
+	Value           string `gorm:"primaryKey"` // e.g. "Austin"
+	HexCount        int
+	CoveredHexCount int
+	FlowerCount     int
+	RewardPCN       float64
+}
+
+var tableNameCoverageRollup = "pollen_coverage_rollups"
+
+func (c *CoverageRollup) TableName() string {
+	return tableNameCoverageRollup
+}
+
+func init() {
+	models = append(models, CoverageRollup{})
+}
+
+// coverageRollupGeographies are the geocoded columns on pollen_hexes rollups are grouped by.
+var coverageRollupGeographies = []string{"city", "county", "state"}
+
+// refreshCoverageRollups recomputes CoverageRollup for every geography, called once at the end
+// of a sync run. Stale values (e.g. a city with no more synced hexes) are dropped; everything
+// else is upserted rather than deleted-and-reinserted, so a concurrent reader never sees an
+// empty gap between the delete and the insert.
+func refreshCoverageRollups(db *gorm.DB) error {
+	for _, geography := range coverageRollupGeographies {
+		if err := refreshCoverageRollup(db, geography); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshCoverageRollup recomputes CoverageRollup rows for one geography column.
+func refreshCoverageRollup(db *gorm.DB, geography string) error {
+	var hexRows []struct {
+		Value           string
+		HexCount        int
+		CoveredHexCount int
+		FlowerCount     int
+	}
+	if err := db.Table(tableNameHex).
+		Select(fmt.Sprintf("%s AS value, COUNT(*) AS hex_count, COUNT(*) FILTER (WHERE covered > 0) AS covered_hex_count, COALESCE(SUM(flower_count), 0) AS flower_count", geography)).
+		Where(fmt.Sprintf("%s <> ''", geography)).
+		Group(geography).
+		Scan(&hexRows).Error; err != nil {
+		return err
+	}
+
+	var rewardRows []struct {
+		Value     string
+		RewardPCN float64
+	}
+	if err := db.Table(tableNameRewardCoverage + " AS rc").
+		Joins(fmt.Sprintf("JOIN %s AS r ON r.id = rc.reward_id", tableNameReward)).
+		Joins(fmt.Sprintf("JOIN %s AS h ON h.id = rc.hex_id", tableNameHex)).
+		Select(fmt.Sprintf("h.%s AS value, COALESCE(SUM(r.pcn), 0) AS reward_pcn", geography)).
+		Where(fmt.Sprintf("h.%s <> ''", geography)).
+		Group(fmt.Sprintf("h.%s", geography)).
+		Scan(&rewardRows).Error; err != nil {
+		return err
+	}
+	rewardPCN := make(map[string]float64, len(rewardRows))
+	for _, r := range rewardRows {
+		rewardPCN[r.Value] = r.RewardPCN
+	}
+
+	rows := make([]CoverageRollup, 0, len(hexRows))
+	values := make([]string, 0, len(hexRows))
+	for _, h := range hexRows {
+		rows = append(rows, CoverageRollup{
+			Geography:       geography,
+			Value:           h.Value,
+			HexCount:        h.HexCount,
+			CoveredHexCount: h.CoveredHexCount,
+			FlowerCount:     h.FlowerCount,
+			RewardPCN:       rewardPCN[h.Value],
+		})
+		values = append(values, h.Value)
+	}
+
+	q := db.Where("geography = ?", geography)
+	if len(values) > 0 {
+		q = q.Where("value NOT IN ?", values)
+	}
+	if err := q.Delete(&CoverageRollup{}).Error; err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "geography"}, {Name: "value"}},
+		DoUpdates: clause.AssignmentColumns([]string{"hex_count", "covered_hex_count", "flower_count", "reward_pcn"}),
+	}).Create(&rows).Error
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DecodeFailure is a dead letter for a hex, flower, or reward batch payload that failed to
+// decode as JSON, so the raw body and error are preserved for later triage instead of the run
+// aborting or the payload being silently dropped.
+type DecodeFailure struct {
+	ID           uint `gorm:"primaryKey"`
+	Kind         string
+	Key          string
+	Body         string
+	ErrorMessage string
+	FailedAt     time.Time
+}
+
+var tableNameDecodeFailure = "pollen_decode_failures"
+
+func (d *DecodeFailure) TableName() string {
+	return tableNameDecodeFailure
+}
+
+func init() {
+	models = append(models, DecodeFailure{})
+}
+
+// writeDecodeFailure appends a dead-letter row for a payload that failed to decode. kind is
+// "hexes", "hex", "flowers", or "rewards"; key identifies the request within that kind (a hex
+// ID, a device name, or empty for the hex/flower list endpoints).
+func writeDecodeFailure(db *gorm.DB, kind, key string, body []byte, decodeErr error) error {
+	return db.Create(&DecodeFailure{
+		Kind:         kind,
+		Key:          key,
+		Body:         string(body),
+		ErrorMessage: decodeErr.Error(),
+		FailedAt:     time.Now(),
+	}).Error
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Delta is one run's network growth snapshot: the net change in the number of covered hexes
+// (hexes losing coverage offset ones gaining it, so it can be negative), how many flowers were
+// added or disappeared, and how much PCN was minted, computed by
+// diffing aggregate counts taken immediately before and after the run. Kept so "network growth"
+// charts don't require diffing pollen_flowers/pollen_hexes snapshots externally.
+type Delta struct {
+	ID              uint `gorm:"primaryKey"`
+	Label           string
+	RecordedAt      time.Time
+	NewHexesCovered int
+	FlowersAdded    int
+	FlowersLost     int
+	PCNMinted       float64
+}
+
+var tableNameDelta = "pollen_deltas"
+
+func (d *Delta) TableName() string {
+	return tableNameDelta
+}
+
+func init() {
+	models = append(models, Delta{})
+}
+
+// deltaSnapshot is the small set of aggregate counts writeDelta diffs before/after a run.
+type deltaSnapshot struct {
+	coveredHexes int64
+	flowerIDs    map[string]bool
+	totalPCN     float64
+}
+
+// captureDeltaSnapshot reads the current aggregate counts deltas are computed from.
+func captureDeltaSnapshot(db *gorm.DB) (deltaSnapshot, error) {
+	var coveredHexes int64
+	if err := db.Table(tableNameHex).Where("covered > 0").Count(&coveredHexes).Error; err != nil {
+		return deltaSnapshot{}, err
+	}
+	var flowerIDs []string
+	if err := db.Table(tableNameFlower).Select("id").Find(&flowerIDs).Error; err != nil {
+		return deltaSnapshot{}, err
+	}
+	ids := make(map[string]bool, len(flowerIDs))
+	for _, id := range flowerIDs {
+		ids[id] = true
+	}
+	var totalPCN float64
+	if err := db.Table(tableNameReward).Select("COALESCE(SUM(pcn), 0)").Row().Scan(&totalPCN); err != nil {
+		return deltaSnapshot{}, err
+	}
+	return deltaSnapshot{coveredHexes: coveredHexes, flowerIDs: ids, totalPCN: totalPCN}, nil
+}
+
+// writeDelta diffs before against after and appends one pollen_deltas row, a no-op under --slim.
+func writeDelta(db *gorm.DB, before, after deltaSnapshot) error {
+	if *slim {
+		return nil
+	}
+	added, lost := 0, 0
+	for id := range after.flowerIDs {
+		if !before.flowerIDs[id] {
+			added++
+		}
+	}
+	for id := range before.flowerIDs {
+		if !after.flowerIDs[id] {
+			lost++
+		}
+	}
+	return db.Create(&Delta{
+		Label:           *label,
+		RecordedAt:      time.Now(),
+		NewHexesCovered: int(after.coveredHexes - before.coveredHexes),
+		FlowersAdded:    added,
+		FlowersLost:     lost,
+		PCNMinted:       after.totalPCN - before.totalPCN,
+	}).Error
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	registerSubcommand("doctor", runDoctorCommand)
+}
+
+// doctorCheck is one diagnostic runDoctorCommand performs: a human-readable name plus a result,
+// printed uniformly regardless of which area (DB, Pollen API, Nominatim, schema) it covers.
+type doctorCheck struct {
+	Name   string
+	OK     bool
+	Detail string
+}
+
+// runDoctorCommand checks DB connectivity and permissions, Pollen API reachability, Nominatim
+// access, and schema drift against the current models, printing an actionable line per check
+// instead of leaving a new user to decode a panic from deep inside runSync.
+func runDoctorCommand(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	fs.Parse(args)
+
+	var checks []doctorCheck
+	db, dbCheck := doctorCheckDB(os.Getenv("PG_URL"))
+	checks = append(checks, dbCheck)
+	if db != nil {
+		checks = append(checks, doctorCheckSchema(db)...)
+	}
+	checks = append(checks, doctorCheckPollenAPI())
+	checks = append(checks, doctorCheckNominatim())
+
+	allOK := true
+	for _, c := range checks {
+		status := "OK"
+		if !c.OK {
+			status = "FAIL"
+			allOK = false
+		}
+		fmt.Printf("[%-4s] %-28s %s\n", status, c.Name, c.Detail)
+	}
+	if !allOK {
+		return ExitRunFailure
+	}
+	return ExitSuccess
+}
+
+// doctorCheckDB verifies PG_URL is set, reachable, and that this process can read/write it,
+// returning the opened *gorm.DB (nil on failure) so doctorCheckSchema can reuse the connection.
+func doctorCheckDB(pgURL string) (*gorm.DB, doctorCheck) {
+	if pgURL == "" {
+		return nil, doctorCheck{Name: "db connection", Detail: "PG_URL is not set"}
+	}
+	db, err := openDB(pgURL)
+	if err != nil {
+		return nil, doctorCheck{Name: "db connection", Detail: fmt.Sprintf("connect failed: %v", err)}
+	}
+	if err := db.Exec("CREATE TABLE IF NOT EXISTS pollen_doctor_probe (id int)").Error; err != nil {
+		return db, doctorCheck{Name: "db connection", Detail: fmt.Sprintf("no create permission: %v", err)}
+	}
+	if err := db.Exec("DROP TABLE pollen_doctor_probe").Error; err != nil {
+		return db, doctorCheck{Name: "db connection", Detail: fmt.Sprintf("no drop permission: %v", err)}
+	}
+	return db, doctorCheck{Name: "db connection", OK: true, Detail: "connected, can create/drop tables"}
+}
+
+// doctorCheckSchema reports, per model, whether its table and every struct field's column
+// exist, so a database that hasn't been migrated recently shows exactly what's missing instead
+// of failing opaquely partway through a sync.
+func doctorCheckSchema(db *gorm.DB) []doctorCheck {
+	var checks []doctorCheck
+	for _, model := range models {
+		name := reflect.TypeOf(model).Name()
+		if !db.Migrator().HasTable(&model) {
+			checks = append(checks, doctorCheck{Name: "schema: " + name, Detail: "table does not exist, run migrate-legacy or sync"})
+			continue
+		}
+		var missing []string
+		t := reflect.TypeOf(model)
+		for i := 0; i < t.NumField(); i++ {
+			if !db.Migrator().HasColumn(&model, t.Field(i).Name) {
+				missing = append(missing, t.Field(i).Name)
+			}
+		}
+		if len(missing) > 0 {
+			checks = append(checks, doctorCheck{Name: "schema: " + name, Detail: fmt.Sprintf("missing columns %v, run migrate-legacy", missing)})
+			continue
+		}
+		checks = append(checks, doctorCheck{Name: "schema: " + name, OK: true, Detail: "up to date"})
+	}
+	return checks
+}
+
+// doctorCheckPollenAPI makes a single, direct (no retries, no cache) request to the Pollen
+// flowers endpoint, distinguishing an invalid/expired key from a broader outage.
+func doctorCheckPollenAPI() doctorCheck {
+	cli := http.Client{Timeout: *httpTimeout}
+	req, err := http.NewRequest(http.MethodGet, pollenAPIFlowers, nil)
+	if err != nil {
+		return doctorCheck{Name: "pollen api", Detail: err.Error()}
+	}
+	for key, value := range pollenAPIHeaders {
+		req.Header.Set(key, value)
+	}
+	res, err := doHTTP(&cli, req)
+	if err != nil {
+		return doctorCheck{Name: "pollen api", Detail: fmt.Sprintf("request failed: %v", err)}
+	}
+	defer res.Body.Close()
+	switch {
+	case res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden:
+		return doctorCheck{Name: "pollen api", Detail: fmt.Sprintf("status %d: x-api-key rejected", res.StatusCode)}
+	case res.StatusCode >= 300:
+		return doctorCheck{Name: "pollen api", Detail: fmt.Sprintf("status %d", res.StatusCode)}
+	default:
+		return doctorCheck{Name: "pollen api", OK: true, Detail: fmt.Sprintf("reachable, status %d", res.StatusCode)}
+	}
+}
+
+// doctorCheckNominatim makes a single reverse-geocode request for a known-good lat/lng, since
+// both reverseGeocode (sync) and geocodeCityName (init) depend on Nominatim being reachable.
+func doctorCheckNominatim() doctorCheck {
+	cli := http.Client{Timeout: *httpTimeout}
+	req, err := http.NewRequest(http.MethodGet, osmAPI(40.7128, -74.0060), nil)
+	if err != nil {
+		return doctorCheck{Name: "nominatim", Detail: err.Error()}
+	}
+	req.Header.Set("user-agent", osmUA)
+	res, err := doHTTP(&cli, req)
+	if err != nil {
+		return doctorCheck{Name: "nominatim", Detail: fmt.Sprintf("request failed: %v", err)}
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		return doctorCheck{Name: "nominatim", Detail: fmt.Sprintf("status %d", res.StatusCode)}
+	}
+	return doctorCheck{Name: "nominatim", OK: true, Detail: fmt.Sprintf("reachable, status %d", res.StatusCode)}
+}
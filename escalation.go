@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// Escalation integrations open an incident in PagerDuty and/or Opsgenie when a run fails
+// --escalate-after-failures times in a row, so a production deployment's on-call gets paged
+// instead of relying on someone noticing a failed cron line. Off by default, like --alert-webhook
+// (bountyalerts.go), which this is the production-incident counterpart to.
+var (
+	pagerdutyRoutingKey   = flag.String("pagerduty-routing-key", "", "PagerDuty Events API v2 integration/routing key; empty disables PagerDuty escalation")
+	opsgenieAPIKey        = flag.String("opsgenie-api-key", "", "Opsgenie Alert API GenieKey; empty disables Opsgenie escalation")
+	escalateAfterFailures = flag.Int("escalate-after-failures", 3, "open an incident after this many consecutive run failures")
+)
+
+// consecutiveFailuresKey is the SyncState key tracking how many runs in a row have failed.
+const consecutiveFailuresKey = "consecutive_failures"
+
+// recordRunOutcome updates the consecutive-failures counter in SyncState and escalates once it
+// reaches --escalate-after-failures, called once at the end of runSync with the run's final
+// pass/fail outcome.
+func recordRunOutcome(db *gorm.DB, failed bool) error {
+	if !failed {
+		return setSyncState(db, consecutiveFailuresKey, "0")
+	}
+	raw, err := getSyncState(db, consecutiveFailuresKey)
+	if err != nil {
+		return err
+	}
+	count, _ := strconv.Atoi(raw)
+	count++
+	if err := setSyncState(db, consecutiveFailuresKey, strconv.Itoa(count)); err != nil {
+		return err
+	}
+	if count >= *escalateAfterFailures {
+		return escalate(db, fmt.Sprintf("pollen sync has failed %d consecutive times", count))
+	}
+	return nil
+}
+
+// escalate opens an incident in every configured escalation integration, a no-op if neither
+// --pagerduty-routing-key nor --opsgenie-api-key is set. It also leaves a Grafana annotation
+// (best-effort; logged, not returned, on failure) so the incident shows up as a marker on
+// whatever panel was being watched at the time.
+func escalate(db *gorm.DB, summary string) error {
+	if err := writeGrafanaAnnotation(db, "pollen escalation", summary, []string{"escalation"}); err != nil {
+		logProgress("writing grafana annotation failed: %v\n", err)
+	}
+	if *pagerdutyRoutingKey != "" {
+		if err := escalatePagerDuty(summary); err != nil {
+			return err
+		}
+	}
+	if *opsgenieAPIKey != "" {
+		if err := escalateOpsgenie(summary); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escalatePagerDuty triggers a PagerDuty Events API v2 incident.
+func escalatePagerDuty(summary string) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"routing_key":  *pagerdutyRoutingKey,
+		"event_action": "trigger",
+		"payload": map[string]string{
+			"summary":  summary,
+			"source":   "pollen-sync",
+			"severity": "critical",
+		},
+	})
+	if err != nil {
+		return err
+	}
+	res, err := http.Post("https://events.pagerduty.com/v2/enqueue", "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("posting PagerDuty event: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("PagerDuty event API returned status %d", res.StatusCode)
+	}
+	return nil
+}
+
+// escalateOpsgenie creates an Opsgenie alert.
+func escalateOpsgenie(summary string) error {
+	payload, err := json.Marshal(map[string]string{"message": summary, "source": "pollen-sync"})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, "https://api.opsgenie.com/v2/alerts", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+*opsgenieAPIKey)
+	cli := http.Client{Timeout: *httpTimeout}
+	res, err := doHTTP(&cli, req)
+	if err != nil {
+		return fmt.Errorf("posting Opsgenie alert: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("Opsgenie alert API returned status %d", res.StatusCode)
+	}
+	return nil
+}
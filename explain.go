@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+)
+
+// explain prints the API calls a run would make and an estimated duration, then exits without
+// touching the database, so users can size a backfill (or sanity-check a config change) before
+// committing to it.
+var explain = flag.Bool("explain", false, "print planned API calls and estimated duration, then exit without syncing anything")
+
+// pollenRateLimitRPS and osmRateLimitRPS mirror the rates pollenRateLimit/osmRateLimit enforce,
+// used only to estimate --explain's duration (the limiters themselves are untouched).
+const (
+	pollenRateLimitRPS = 2.0
+	osmRateLimitRPS    = 1.0
+)
+
+// runExplain lists the hexes and flowers the run would fetch (which itself costs one API call
+// per hex group plus one for the flower list) and uses those counts to estimate the rest: one
+// hex-details and one geocode call per hex, and one rewards call per flower.
+func runExplain(hexGroups []string) error {
+	hexCalls := len(hexGroups)
+	totalHexes := 0
+	for _, hexGroup := range hexGroups {
+		hexes, err := getAllHexes(hexGroup)
+		if err != nil {
+			return err
+		}
+		totalHexes += len(hexes)
+	}
+
+	flowerItems, err := getAllFlowers()
+	if err != nil {
+		return err
+	}
+	totalFlowers := len(flowerItems)
+
+	pollenCalls := hexCalls + 1 /* flower list */ + totalHexes /* hex details */ + totalFlowers /* rewards */
+	osmCalls := totalHexes + totalFlowers
+
+	fmt.Println("Planned API calls:")
+	fmt.Printf("  pollen hex list:     %d call(s) across %d hex group(s)\n", hexCalls, len(hexGroups))
+	fmt.Printf("  pollen hex details:  %d call(s), one per hex (%d hexes found)\n", totalHexes, totalHexes)
+	fmt.Printf("  pollen flower list:  1 call (%d flowers found)\n", totalFlowers)
+	fmt.Printf("  pollen rewards:      %d call(s), one per flower\n", totalFlowers)
+	fmt.Printf("  osm reverse geocode: %d call(s), one per hex and flower (cache hits will reduce this)\n", osmCalls)
+
+	pollenSeconds := float64(pollenCalls) / pollenRateLimitRPS
+	osmSeconds := float64(osmCalls) / osmRateLimitRPS
+	estimated := time.Duration(pollenSeconds+osmSeconds) * time.Second
+	fmt.Printf("Estimated duration at current rate limits: %s (upper bound; geocode cache hits will make it faster)\n", estimated)
+	return nil
+}
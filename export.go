@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+
+	_ "github.com/mattn/go-sqlite3"
+	"gorm.io/gorm"
+)
+
+func init() {
+	registerSubcommand("export", runExportCommand)
+}
+
+// runExportCommand dispatches `export <subcommand>`.
+func runExportCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: export tiles|opencellid ...")
+		return ExitRunFailure
+	}
+	switch args[0] {
+	case "tiles":
+		return runExportTiles(args[1:])
+	case "opencellid":
+		return runExportOpenCelliD(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown export subcommand %q\n", args[0])
+		return ExitRunFailure
+	}
+}
+
+// runExportTiles bakes current hex coverage and flower points into an MBTiles file covering
+// --zoom-min through --zoom-max, for offline or static hosting of a coverage map. Each tile's
+// content is a GeoJSON FeatureCollection (not a full MVT/protobuf encoder, to avoid pulling in
+// a second heavyweight tile-encoding dependency), gzip-compressed the same way MBTiles tiles
+// normally are.
+func runExportTiles(args []string) int {
+	fs := flag.NewFlagSet("export tiles", flag.ExitOnError)
+	out := fs.String("out", "coverage.mbtiles", "path to write the MBTiles file to")
+	region := fs.String("region", "", "only export hexes whose region (H3HexTop) matches this value")
+	zoomMin := fs.Int("zoom-min", 10, "minimum zoom level to generate tiles for")
+	zoomMax := fs.Int("zoom-max", 14, "maximum zoom level to generate tiles for")
+	fs.Parse(args)
+
+	db, err := openDB(os.Getenv("PG_URL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db connect failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	bounds, err := loadCoverageBounds(db, *region)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "query failed: %v\n", err)
+		return ExitDBFailure
+	}
+	if bounds.empty {
+		fmt.Println("no hexes with coordinates found, nothing to export")
+		return ExitSuccess
+	}
+
+	os.Remove(*out)
+	sqliteDB, err := sql.Open("sqlite3", *out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "opening %s failed: %v\n", *out, err)
+		return ExitRunFailure
+	}
+	defer sqliteDB.Close()
+
+	if err := initMBTilesSchema(sqliteDB, bounds, *zoomMin, *zoomMax); err != nil {
+		fmt.Fprintf(os.Stderr, "schema init failed: %v\n", err)
+		return ExitRunFailure
+	}
+
+	tileCount := 0
+	for z := *zoomMin; z <= *zoomMax; z++ {
+		minX, minY, maxX, maxY := tileRangeAt(bounds, z)
+		for x := minX; x <= maxX; x++ {
+			for y := minY; y <= maxY; y++ {
+				hexFeatures, err := hexFeaturesForTile(db, z, x, y)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "tile query failed: %v\n", err)
+					return ExitDBFailure
+				}
+				flowerFeatures, err := flowerFeaturesForTile(db, z, x, y)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "tile query failed: %v\n", err)
+					return ExitDBFailure
+				}
+				features := append(hexFeatures, flowerFeatures...)
+				if len(features) == 0 {
+					continue
+				}
+				if err := writeMBTile(sqliteDB, z, x, y, features); err != nil {
+					fmt.Fprintf(os.Stderr, "writing tile %d/%d/%d failed: %v\n", z, x, y, err)
+					return ExitRunFailure
+				}
+				tileCount++
+			}
+		}
+	}
+
+	fmt.Printf("wrote %d tiles to %s\n", tileCount, *out)
+	return ExitSuccess
+}
+
+// coverageBounds is the lat/lng bounding box tiles need to be generated for.
+type coverageBounds struct {
+	minLat, minLng, maxLat, maxLng float64
+	empty                          bool
+}
+
+// loadCoverageBounds queries the bounding box of every hex with coordinates, optionally scoped to
+// region (H3HexTop).
+func loadCoverageBounds(db *gorm.DB, region string) (coverageBounds, error) {
+	q := db.Table(tableNameHex).Where("lat <> 0 OR lng <> 0")
+	if region != "" {
+		q = q.Where("h3_hex_top = ?", region)
+	}
+	var agg struct {
+		MinLat, MaxLat, MinLng, MaxLng float64
+		Count                          int64
+	}
+	if err := q.Select("MIN(lat) AS min_lat, MAX(lat) AS max_lat, MIN(lng) AS min_lng, MAX(lng) AS max_lng, COUNT(*) AS count").
+		Scan(&agg).Error; err != nil {
+		return coverageBounds{}, err
+	}
+	if agg.Count == 0 {
+		return coverageBounds{empty: true}, nil
+	}
+	return coverageBounds{minLat: agg.MinLat, minLng: agg.MinLng, maxLat: agg.MaxLat, maxLng: agg.MaxLng}, nil
+}
+
+// tileRangeAt returns the inclusive tile x/y range covering bounds at zoom z.
+func tileRangeAt(bounds coverageBounds, z int) (minX, minY, maxX, maxY int) {
+	x1, y1 := lngLatToTile(bounds.minLng, bounds.maxLat, z)
+	x2, y2 := lngLatToTile(bounds.maxLng, bounds.minLat, z)
+	return min(x1, x2), min(y1, y2), max(x1, x2), max(y1, y2)
+}
+
+// lngLatToTile converts a lng/lat pair to the slippy-map tile x/y containing it at zoom z, the
+// inverse of serve.go's tileBounds.
+func lngLatToTile(lng, lat float64, z int) (x, y int) {
+	n := math.Pow(2, float64(z))
+	x = int((lng + 180.0) / 360.0 * n)
+	latRad := lat * math.Pi / 180.0
+	y = int((1 - math.Log(math.Tan(latRad)+1/math.Cos(latRad))/math.Pi) / 2 * n)
+	return x, y
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// initMBTilesSchema creates the standard MBTiles metadata/tiles tables and records metadata.
+func initMBTilesSchema(db *sql.DB, bounds coverageBounds, zoomMin, zoomMax int) error {
+	stmts := []string{
+		"CREATE TABLE metadata (name TEXT, value TEXT)",
+		"CREATE TABLE tiles (zoom_level INTEGER, tile_column INTEGER, tile_row INTEGER, tile_data BLOB)",
+		"CREATE UNIQUE INDEX tile_index ON tiles (zoom_level, tile_column, tile_row)",
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	metadata := map[string]string{
+		"name":        "pollen coverage",
+		"format":      "geojson",
+		"minzoom":     fmt.Sprint(zoomMin),
+		"maxzoom":     fmt.Sprint(zoomMax),
+		"bounds":      fmt.Sprintf("%f,%f,%f,%f", bounds.minLng, bounds.minLat, bounds.maxLng, bounds.maxLat),
+		"description": "Pollen Mobile hex coverage and flower points baked from pollen_hexes/pollen_flowers",
+	}
+	for name, value := range metadata {
+		if _, err := db.Exec("INSERT INTO metadata (name, value) VALUES (?, ?)", name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMBTile gzip-compresses features as a GeoJSON FeatureCollection and inserts it into the
+// tiles table, flipping y to the TMS scheme MBTiles uses.
+func writeMBTile(db *sql.DB, z, x, y int, features []geoJSONFeature) error {
+	raw, err := json.Marshal(geoJSONFeatureCollection{Type: "FeatureCollection", Features: features})
+	if err != nil {
+		return err
+	}
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	if _, err := w.Write(raw); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	tmsY := (1 << uint(z)) - 1 - y
+	_, err = db.Exec("INSERT INTO tiles (zoom_level, tile_column, tile_row, tile_data) VALUES (?, ?, ?, ?)", z, x, tmsY, gz.Bytes())
+	return err
+}
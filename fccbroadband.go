@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/uber/h3-go/v4"
+	"gorm.io/gorm/clause"
+)
+
+func init() {
+	registerSubcommand("fcc", runFCCCommand)
+	models = append(models, FCCBroadbandRecord{})
+}
+
+// fccResolution is the H3 resolution FCC broadband records are bucketed to, matching
+// heliumhotspots.go's heliumResolution so the two overlays compare against Pollen hexes the
+// same way.
+const fccResolution = 8
+
+// fccUnservedDownMbps and fccUnservedUpMbps are the FCC's "unserved" broadband thresholds: a
+// location with less than this is counted as underserved by underservedFCCRecord below.
+const (
+	fccUnservedDownMbps = 25.0
+	fccUnservedUpMbps   = 3.0
+)
+
+// FCCBroadbandRecord is one H3 cell's best advertised broadband speed from an imported FCC
+// broadband availability dataset, for joint underserved/coverage views against Pollen hexes.
+type FCCBroadbandRecord struct {
+	H3Hex           string `gorm:"primaryKey"`
+	MaxDownloadMbps float64
+	MaxUploadMbps   float64
+	ProviderCount   int
+	ImportedAt      time.Time `gorm:"not null;default:current_timestamp"`
+}
+
+var tableNameFCCBroadbandRecord = "pollen_fcc_broadband_records"
+
+func (r *FCCBroadbandRecord) TableName() string {
+	return tableNameFCCBroadbandRecord
+}
+
+// underservedFCCRecord reports whether r falls below the FCC's unserved broadband thresholds.
+func underservedFCCRecord(r FCCBroadbandRecord) bool {
+	return r.MaxDownloadMbps < fccUnservedDownMbps || r.MaxUploadMbps < fccUnservedUpMbps
+}
+
+// runFCCCommand dispatches `fcc <subcommand>`.
+func runFCCCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: fcc import|compare ...")
+		return ExitRunFailure
+	}
+	switch args[0] {
+	case "import":
+		return runFCCImport(args[1:])
+	case "compare":
+		return runFCCCompare(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown fcc subcommand %q\n", args[0])
+		return ExitRunFailure
+	}
+}
+
+// runFCCImport loads an FCC broadband availability CSV (expects either an h3 column, or lat/lng
+// to derive one, plus max_download_mbps/max_upload_mbps and optional provider_count) and upserts
+// it into pollen_fcc_broadband_records.
+func runFCCImport(args []string) int {
+	fs := flag.NewFlagSet("fcc import", flag.ExitOnError)
+	file := fs.String("file", "", "path to an FCC broadband availability CSV export")
+	fs.Parse(args)
+	if *file == "" {
+		fmt.Fprintln(os.Stderr, "usage: fcc import --file broadband.csv")
+		return ExitRunFailure
+	}
+
+	records, err := readFCCBroadbandRecords(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read %s: %v\n", *file, err)
+		return ExitRunFailure
+	}
+	if len(records) == 0 {
+		fmt.Println("no records to import")
+		return ExitSuccess
+	}
+
+	db, err := openDB(os.Getenv("PG_URL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db connect failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	err = withDBRetry(func() error {
+		return db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "h3_hex"}},
+			UpdateAll: true,
+		}).CreateInBatches(&records, 200).Error
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import failed: %v\n", err)
+		return ExitDBFailure
+	}
+	fmt.Printf("imported %d FCC broadband records\n", len(records))
+	return ExitSuccess
+}
+
+// readFCCBroadbandRecords parses an FCC broadband CSV (header row = column names) into
+// FCCBroadbandRecord rows, deriving h3 from lat/lng when an h3 column isn't present. Rows that
+// are missing both an h3 and a lat/lng pair are skipped.
+func readFCCBroadbandRecords(path string) ([]FCCBroadbandRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	rawRecords, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rawRecords) == 0 {
+		return nil, nil
+	}
+	col := map[string]int{}
+	for i, name := range rawRecords[0] {
+		col[name] = i
+	}
+	get := func(record []string, name string) string {
+		i, ok := col[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+
+	records := make([]FCCBroadbandRecord, 0, len(rawRecords)-1)
+	for _, record := range rawRecords[1:] {
+		h3Hex := get(record, "h3")
+		if h3Hex == "" {
+			lat, latErr := strconv.ParseFloat(get(record, "lat"), 64)
+			lng, lngErr := strconv.ParseFloat(get(record, "lng"), 64)
+			if latErr != nil || lngErr != nil {
+				continue
+			}
+			h3Hex = h3.LatLngToCell(h3.LatLng{Lat: lat, Lng: lng}, fccResolution).String()
+		}
+		maxDown, _ := strconv.ParseFloat(get(record, "max_download_mbps"), 64)
+		maxUp, _ := strconv.ParseFloat(get(record, "max_upload_mbps"), 64)
+		providerCount, _ := strconv.Atoi(get(record, "provider_count"))
+		records = append(records, FCCBroadbandRecord{
+			H3Hex:           h3Hex,
+			MaxDownloadMbps: maxDown,
+			MaxUploadMbps:   maxUp,
+			ProviderCount:   providerCount,
+		})
+	}
+	return records, nil
+}
+
+// fccUnderservedHexRow is one underserved hex and whether Pollen covers it.
+type fccUnderservedHexRow struct {
+	H3Hex           string  `json:"h3_hex"`
+	MaxDownloadMbps float64 `json:"max_download_mbps"`
+	MaxUploadMbps   float64 `json:"max_upload_mbps"`
+	PollenCovered   bool    `json:"pollen_covered"`
+}
+
+// runFCCCompare lists underserved hexes (per the FCC unserved thresholds) alongside whether
+// Pollen already covers them, optionally scoped to region (H3HexTop), useful for grant
+// applications showing where Pollen coverage is filling (or could fill) a documented gap.
+func runFCCCompare(args []string) int {
+	fs := flag.NewFlagSet("fcc compare", flag.ExitOnError)
+	region := fs.String("region", "", "only include hexes whose region (H3HexTop) matches this value")
+	format := fs.String("format", "table", "output format: table or json")
+	fs.Parse(args)
+
+	db, err := openDB(os.Getenv("PG_URL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db connect failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	pollenCells, err := pollenHeliumResCells(db, *region)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "query failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	var fccRecords []FCCBroadbandRecord
+	if err := db.Find(&fccRecords).Error; err != nil {
+		fmt.Fprintf(os.Stderr, "query failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	var rows []fccUnderservedHexRow
+	coveredCount := 0
+	for _, rec := range fccRecords {
+		if !underservedFCCRecord(rec) {
+			continue
+		}
+		covered := pollenCells[rec.H3Hex]
+		if covered {
+			coveredCount++
+		}
+		rows = append(rows, fccUnderservedHexRow{
+			H3Hex:           rec.H3Hex,
+			MaxDownloadMbps: rec.MaxDownloadMbps,
+			MaxUploadMbps:   rec.MaxUploadMbps,
+			PollenCovered:   covered,
+		})
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(rows)
+	default:
+		fmt.Printf("%d underserved hexes, %d already covered by Pollen\n", len(rows), coveredCount)
+		fmt.Printf("%-18s %12s %12s %10s\n", "H3Hex", "Down Mbps", "Up Mbps", "Covered")
+		for _, r := range rows {
+			fmt.Printf("%-18s %12.2f %12.2f %10t\n", r.H3Hex, r.MaxDownloadMbps, r.MaxUploadMbps, r.PollenCovered)
+		}
+	}
+	return ExitSuccess
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// strictSchema, when set, adds foreign-key constraints from rewards to flowers and from reward
+// coverage to hexes, so a bug that writes a reward for an unknown flower, or coverage for an
+// unknown hex, fails loudly at insert time instead of silently corrupting downstream joins. Off
+// by default: sync order isn't guaranteed (rewards can be fetched for a flower before flower sync
+// has written its row on a fresh database), and a failed insert there is worse than the odd
+// orphaned row the repair command can clean up later.
+var strictSchema = flag.Bool("strict-schema", false, "add FK constraints from rewards/coverage to flowers/hexes; rejects inserts referencing unknown rows")
+
+// foreignKey is one FK constraint applyStrictSchema can add.
+type foreignKey struct {
+	name, table, column, refTable, refColumn string
+}
+
+var foreignKeys = []foreignKey{
+	{"fk_pollen_rewards_device", tableNameReward, "device", tableNameFlower, "id"},
+	{"fk_pollen_reward_coverage_hex", tableNameRewardCoverage, "hex_id", tableNameHex, "id"},
+}
+
+// applyStrictSchema adds foreignKeys's constraints, a no-op unless --strict-schema is set.
+// Postgres has no ADD CONSTRAINT IF NOT EXISTS, so each constraint's presence is checked against
+// pg_constraint first, making a repeated run against an already-migrated database a no-op.
+func applyStrictSchema(db *gorm.DB) error {
+	if !*strictSchema {
+		return nil
+	}
+	for _, fk := range foreignKeys {
+		if err := addForeignKeyIfMissing(db, fk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addForeignKeyIfMissing adds fk unless pg_constraint already has it.
+func addForeignKeyIfMissing(db *gorm.DB, fk foreignKey) error {
+	var count int64
+	if err := db.Raw("SELECT count(*) FROM pg_constraint WHERE conname = ?", fk.name).Scan(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	stmt := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)", fk.table, fk.name, fk.column, fk.refTable, fk.refColumn)
+	return db.Exec(stmt).Error
+}
+
+// flowerForeignKeys returns the subset of foreignKeys that reference pollen_flowers: the ones
+// syncFlowersViaStaging's rename dance has to drop and re-add around the swap.
+func flowerForeignKeys() []foreignKey {
+	var out []foreignKey
+	for _, fk := range foreignKeys {
+		if fk.refTable == tableNameFlower {
+			out = append(out, fk)
+		}
+	}
+	return out
+}
+
+// dropFlowerForeignKeys drops every FK constraint referencing pollen_flowers, a no-op unless
+// --strict-schema is set. Postgres resolves a constraint's reference by the referenced table's
+// OID, not its name, so a live FK still points at the about-to-be-renamed-away pollen_flowers
+// even after --staging-swap's rename dance swaps a new table into that name, which blocks the
+// dance's final DROP TABLE on the old one. syncFlowersViaStaging calls this before the rename and
+// addFlowerForeignKeys after, both inside the same transaction as the swap.
+func dropFlowerForeignKeys(db *gorm.DB) error {
+	if !*strictSchema {
+		return nil
+	}
+	for _, fk := range flowerForeignKeys() {
+		if err := db.Exec(fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s", fk.table, fk.name)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addFlowerForeignKeys re-adds the constraints dropFlowerForeignKeys removed, against the
+// just-swapped-in pollen_flowers.
+func addFlowerForeignKeys(db *gorm.DB) error {
+	if !*strictSchema {
+		return nil
+	}
+	for _, fk := range flowerForeignKeys() {
+		if err := addForeignKeyIfMissing(db, fk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func init() {
+	registerSubcommand("flower", runFlowerCommand)
+}
+
+// runFlowerCommand dispatches `flower <subcommand>`.
+func runFlowerCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: flower find --nickname <name> | --wallet <addr>")
+		return ExitRunFailure
+	}
+	switch args[0] {
+	case "find":
+		return runFlowerFind(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown flower subcommand %q\n", args[0])
+		return ExitRunFailure
+	}
+}
+
+// runFlowerFind queries the local DB for flowers matching --nickname and/or --wallet, printing
+// location, status, and recent rewards for each match — a quick check without reaching for psql.
+func runFlowerFind(args []string) int {
+	fs := flag.NewFlagSet("flower find", flag.ExitOnError)
+	nickname := fs.String("nickname", "", "match flowers whose nickname contains this (case-insensitive)")
+	wallet := fs.String("wallet", "", "match flowers with this exact wallet address")
+	fs.Parse(args)
+
+	if *nickname == "" && *wallet == "" {
+		fmt.Fprintln(os.Stderr, "flower find requires --nickname or --wallet")
+		return ExitRunFailure
+	}
+
+	db, err := openDB(os.Getenv("PG_URL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db connect failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	q := db.Table(tableNameFlower)
+	if *nickname != "" {
+		q = q.Where("nickname ILIKE ?", "%"+*nickname+"%")
+	}
+	if *wallet != "" {
+		q = q.Where("wallet_address = ?", *wallet)
+	}
+	var flowers []Flower
+	if err := q.Find(&flowers).Error; err != nil {
+		fmt.Fprintf(os.Stderr, "query failed: %v\n", err)
+		return ExitDBFailure
+	}
+	if len(flowers) == 0 {
+		fmt.Println("no matching flowers found")
+		return ExitSuccess
+	}
+
+	for _, f := range flowers {
+		status := "inactive"
+		if f.Active != 0 {
+			status = "active"
+		}
+		location := coalesce(f.City, f.Town, f.County, f.Suburb, f.Address)
+		fmt.Printf("%s\t%s\t%s\t%s\trewards=%.2f PCN\n", f.ID, f.Nickname, status, location, f.FlowerRewards)
+
+		var recent []Reward
+		db.Table(tableNameReward).Where("device = ?", f.ID).Order("date DESC").Limit(5).Find(&recent)
+		for _, r := range recent {
+			fmt.Printf("    %s  %s  PCN=%.4f PIC=%.4f\n", r.Date.Format(rewardDateLayout), r.Reward, r.PCN, r.PIC)
+		}
+	}
+	return ExitSuccess
+}
+
+// coalesce returns the first non-empty value, or "" if all are empty.
+func coalesce(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
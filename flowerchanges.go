@@ -0,0 +1,102 @@
+package main
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FlowerChange is an append-only audit log of identity-bearing flower fields that the upsert
+// would otherwise silently overwrite, so a rename doesn't erase the record of who the flower
+// used to be.
+type FlowerChange struct {
+	ID        uint   `gorm:"primaryKey"`
+	FlowerID  string `gorm:"index"`
+	Field     string
+	OldValue  string
+	NewValue  string
+	ChangedAt time.Time
+}
+
+var tableNameFlowerChange = "pollen_flower_changes"
+
+func (c *FlowerChange) TableName() string {
+	return tableNameFlowerChange
+}
+
+func init() {
+	models = append(models, FlowerChange{})
+}
+
+// flowerIdentity holds the fields of a flower whose changes are worth auditing: who it is
+// (flowerchanges.go) and who owns it (flowertransfers.go).
+type flowerIdentity struct {
+	Nickname      string
+	DisplayName   string
+	ImageURL      string
+	WalletAddress string
+	NFTAddress    string
+	H3Hex         string
+}
+
+// loadFlowerIdentities returns the currently-stored identity fields for every flower, keyed by
+// ID, so the next upsert can be diffed against them before it overwrites anything.
+func loadFlowerIdentities(db *gorm.DB) (map[string]flowerIdentity, error) {
+	var rows []struct {
+		ID            string
+		Nickname      string
+		DisplayName   string
+		ImageURL      string
+		WalletAddress string
+		NFTAddress    string
+		H3Hex         string
+	}
+	if err := db.Table(tableNameFlower).Select("id", "nickname", "display_name", "image_url", "wallet_address", "nft_address", "h3_hex").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[string]flowerIdentity, len(rows))
+	for _, r := range rows {
+		out[r.ID] = flowerIdentity{
+			Nickname:      r.Nickname,
+			DisplayName:   r.DisplayName,
+			ImageURL:      r.ImageURL,
+			WalletAddress: r.WalletAddress,
+			NFTAddress:    r.NFTAddress,
+			H3Hex:         r.H3Hex,
+		}
+	}
+	return out, nil
+}
+
+// detectFlowerChanges diffs each flower's identity fields against previous, the values loaded
+// by loadFlowerIdentities before this sync's upsert. A flower absent from previous (first time
+// seen) is not reported as a change.
+func detectFlowerChanges(previous map[string]flowerIdentity, flowers []Flower) []FlowerChange {
+	now := time.Now()
+	var changes []FlowerChange
+	for _, f := range flowers {
+		old, ok := previous[f.ID]
+		if !ok {
+			continue
+		}
+		if old.Nickname != f.Nickname {
+			changes = append(changes, FlowerChange{FlowerID: f.ID, Field: "nickname", OldValue: old.Nickname, NewValue: f.Nickname, ChangedAt: now})
+		}
+		if old.DisplayName != f.DisplayName {
+			changes = append(changes, FlowerChange{FlowerID: f.ID, Field: "display_name", OldValue: old.DisplayName, NewValue: f.DisplayName, ChangedAt: now})
+		}
+		if old.ImageURL != f.ImageURL {
+			changes = append(changes, FlowerChange{FlowerID: f.ID, Field: "image_url", OldValue: old.ImageURL, NewValue: f.ImageURL, ChangedAt: now})
+		}
+	}
+	return changes
+}
+
+// writeFlowerChanges appends changes to pollen_flower_changes, a no-op under --slim or when
+// there's nothing to record.
+func writeFlowerChanges(db *gorm.DB, changes []FlowerChange) error {
+	if *slim || len(changes) == 0 {
+		return nil
+	}
+	return db.CreateInBatches(&changes, 200).Error
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FlowerHistory is an append-only log of a flower's LastSeen snapshot at each sync, the basis
+// for uptime reporting: a flower with a history row every day of the month was up the whole
+// month, one missing a week's worth of rows was down that week.
+type FlowerHistory struct {
+	ID         uint   `gorm:"primaryKey"`
+	FlowerID   string `gorm:"index"`
+	LastSeen   *time.Time
+	RecordedAt time.Time
+}
+
+var tableNameFlowerHistory = "pollen_flower_history"
+
+func (h *FlowerHistory) TableName() string {
+	return tableNameFlowerHistory
+}
+
+func init() {
+	models = append(models, FlowerHistory{})
+}
+
+// writeFlowerHistory appends one snapshot row per flower, a no-op under --slim for the same
+// reason writeHexHistory is: it's history that trades storage for trend visibility later. If
+// --since is set, flowers not seen since the cutoff are skipped too, so standing up a fresh
+// database doesn't immediately start snapshotting devices that are effectively dead.
+func writeFlowerHistory(db *gorm.DB, flowers []Flower) error {
+	if *slim || len(flowers) == 0 {
+		return nil
+	}
+	cutoff, cutoffSet := sinceCutoff()
+	now := time.Now()
+	rows := make([]FlowerHistory, 0, len(flowers))
+	for _, f := range flowers {
+		if cutoffSet && f.LastSeen != nil && f.LastSeen.Before(cutoff) {
+			continue
+		}
+		rows = append(rows, FlowerHistory{FlowerID: f.ID, LastSeen: f.LastSeen, RecordedAt: now})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return db.CreateInBatches(&rows, 200).Error
+}
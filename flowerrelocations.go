@@ -0,0 +1,65 @@
+package main
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FlowerRelocation is an append-only log of a flower's H3Hex changing between syncs, since a
+// relocation affects which hex's coverage/bounty commitments the flower counts toward.
+type FlowerRelocation struct {
+	ID           uint   `gorm:"primaryKey"`
+	FlowerID     string `gorm:"index"`
+	OldHex       string
+	NewHex       string
+	GridDistance int
+	RelocatedAt  time.Time
+}
+
+var tableNameFlowerRelocation = "pollen_flower_relocations"
+
+func (r *FlowerRelocation) TableName() string {
+	return tableNameFlowerRelocation
+}
+
+func init() {
+	models = append(models, FlowerRelocation{})
+}
+
+// detectFlowerRelocations diffs each flower's H3Hex against previous, the values loaded by
+// loadFlowerIdentities before this sync's upsert. A flower absent from previous (first time
+// seen) is not reported as a relocation. GridDistance is left at 0 if either hex fails to parse
+// rather than dropping the relocation, since the relocation itself is still worth recording.
+func detectFlowerRelocations(previous map[string]flowerIdentity, flowers []Flower) []FlowerRelocation {
+	now := time.Now()
+	var relocations []FlowerRelocation
+	for _, f := range flowers {
+		old, ok := previous[f.ID]
+		if !ok || old.H3Hex == f.H3Hex {
+			continue
+		}
+		distance, _ := h3GridDistance(old.H3Hex, f.H3Hex)
+		relocations = append(relocations, FlowerRelocation{
+			FlowerID:     f.ID,
+			OldHex:       old.H3Hex,
+			NewHex:       f.H3Hex,
+			GridDistance: distance,
+			RelocatedAt:  now,
+		})
+	}
+	return relocations
+}
+
+// writeFlowerRelocations appends relocations to pollen_flower_relocations, a no-op under --slim
+// or when there's nothing to record. Each relocation is also logged as progress output; no
+// --alert-webhook alert is sent for it.
+func writeFlowerRelocations(db *gorm.DB, relocations []FlowerRelocation) error {
+	if *slim || len(relocations) == 0 {
+		return nil
+	}
+	for _, r := range relocations {
+		logProgress("Flower %s relocated from %s to %s (%d grid steps)\n", r.FlowerID, r.OldHex, r.NewHex, r.GridDistance)
+	}
+	return db.CreateInBatches(&relocations, 200).Error
+}
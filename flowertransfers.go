@@ -0,0 +1,64 @@
+package main
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FlowerTransfer is an append-only log of flower ownership changes: a changed WalletAddress or
+// NFTAddress between syncs means a secondary-market sale or fleet reorganization happened, which
+// the upsert would otherwise overwrite without a trace.
+type FlowerTransfer struct {
+	ID            uint   `gorm:"primaryKey"`
+	FlowerID      string `gorm:"index"`
+	OldWallet     string
+	NewWallet     string
+	OldNFT        string
+	NewNFT        string
+	TransferredAt time.Time
+}
+
+var tableNameFlowerTransfer = "pollen_flower_transfers"
+
+func (t *FlowerTransfer) TableName() string {
+	return tableNameFlowerTransfer
+}
+
+func init() {
+	models = append(models, FlowerTransfer{})
+}
+
+// detectFlowerTransfers diffs each flower's WalletAddress/NFTAddress against previous, the
+// values loaded by loadFlowerIdentities before this sync's upsert. A flower absent from
+// previous (first time seen) is not reported as a transfer.
+func detectFlowerTransfers(previous map[string]flowerIdentity, flowers []Flower) []FlowerTransfer {
+	now := time.Now()
+	var transfers []FlowerTransfer
+	for _, f := range flowers {
+		old, ok := previous[f.ID]
+		if !ok {
+			continue
+		}
+		if old.WalletAddress != f.WalletAddress || old.NFTAddress != f.NFTAddress {
+			transfers = append(transfers, FlowerTransfer{
+				FlowerID:      f.ID,
+				OldWallet:     old.WalletAddress,
+				NewWallet:     f.WalletAddress,
+				OldNFT:        old.NFTAddress,
+				NewNFT:        f.NFTAddress,
+				TransferredAt: now,
+			})
+		}
+	}
+	return transfers
+}
+
+// writeFlowerTransfers appends transfers to pollen_flower_transfers, a no-op under --slim or
+// when there's nothing to record.
+func writeFlowerTransfers(db *gorm.DB, transfers []FlowerTransfer) error {
+	if *slim || len(transfers) == 0 {
+		return nil
+	}
+	return db.CreateInBatches(&transfers, 200).Error
+}
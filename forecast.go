@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func init() {
+	registerSubcommand("forecast", runForecastCommand)
+	models = append(models, EarningsForecast{})
+}
+
+// EarningsForecast is one device's projected PCN earnings for one future date, regenerated
+// (and upserted) each time the forecast command runs, so operators can pull a rolling ROI
+// projection out of the database instead of re-running the model themselves.
+type EarningsForecast struct {
+	Device       string    `gorm:"primaryKey"`
+	ForecastDate time.Time `gorm:"primaryKey;type:date"`
+	ProjectedPCN float64
+	Model        string
+	GeneratedAt  time.Time `gorm:"not null;default:current_timestamp"`
+}
+
+var tableNameEarningsForecast = "pollen_earnings_forecasts"
+
+func (f *EarningsForecast) TableName() string {
+	return tableNameEarningsForecast
+}
+
+// forecastUpsertClause upserts on (device, forecast_date) so re-running the model against the
+// same horizon overwrites its own prior projection instead of accumulating duplicates.
+var forecastUpsertClause = clause.OnConflict{
+	Columns:   []clause.Column{{Name: "device"}, {Name: "forecast_date"}},
+	UpdateAll: true,
+	DoUpdates: []clause.Assignment{{Column: clause.Column{Name: "generated_at"}, Value: time.Now()}},
+}
+
+// dailyEarnings is one device's observed PCN total for one historical day.
+type dailyEarnings struct {
+	Date time.Time
+	PCN  float64
+}
+
+// runForecastCommand fits a trailing-average/day-of-week-seasonal model per device against
+// --history days of pollen_rewards and projects PCN earnings for the next --horizon days,
+// storing the result in pollen_earnings_forecasts and printing it the way `report` commands do.
+func runForecastCommand(args []string) int {
+	fs := flag.NewFlagSet("forecast", flag.ExitOnError)
+	device := fs.String("device", "", "only forecast this device; empty forecasts every device with reward history")
+	historyDays := fs.Int("history", 90, "days of reward history to fit the model on")
+	horizonDays := fs.Int("horizon", 30, "number of future days to project")
+	format := fs.String("format", "table", "output format: table, csv, or json")
+	fs.Parse(args)
+
+	db, err := openDB(os.Getenv("PG_URL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db connect failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	devices, err := forecastDevices(db, *device)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "query failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	since := time.Now().AddDate(0, 0, -*historyDays)
+	var forecasts []EarningsForecast
+	for _, d := range devices {
+		history, err := deviceDailyEarnings(db, d, since)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "query failed: %v\n", err)
+			return ExitDBFailure
+		}
+		forecasts = append(forecasts, projectEarnings(d, history, *horizonDays)...)
+	}
+
+	if len(forecasts) > 0 {
+		if err := db.Clauses(forecastUpsertClause).CreateInBatches(&forecasts, 200).Error; err != nil {
+			fmt.Fprintf(os.Stderr, "write failed: %v\n", err)
+			return ExitDBFailure
+		}
+	}
+
+	printForecasts(forecasts, *format)
+	return ExitSuccess
+}
+
+// forecastDevices returns the distinct devices with reward history, or just device if it's set.
+func forecastDevices(db *gorm.DB, device string) ([]string, error) {
+	if device != "" {
+		return []string{device}, nil
+	}
+	var devices []string
+	err := db.Table(tableNameReward).Distinct("device").Pluck("device", &devices).Error
+	return devices, err
+}
+
+// deviceDailyEarnings returns device's PCN total per day since since, ordered oldest first.
+func deviceDailyEarnings(db *gorm.DB, device string, since time.Time) ([]dailyEarnings, error) {
+	var rows []dailyEarnings
+	err := db.Table(tableNameReward).
+		Select("date, SUM(pcn) AS pcn").
+		Where("device = ? AND date >= ?", device, since).
+		Group("date").
+		Order("date ASC").
+		Find(&rows).Error
+	return rows, err
+}
+
+// projectEarnings fits a trailing average with a day-of-week seasonal factor against history and
+// projects PCN for each of the next horizonDays days. With no history the trailing average is 0
+// and every projection is 0 rather than skipping the device, so a newly-added device still gets
+// a (zero) row instead of silently vanishing from the report.
+func projectEarnings(device string, history []dailyEarnings, horizonDays int) []EarningsForecast {
+	var total float64
+	weekdayTotal := map[time.Weekday]float64{}
+	weekdayCount := map[time.Weekday]int{}
+	for _, h := range history {
+		total += h.PCN
+		weekdayTotal[h.Date.Weekday()] += h.PCN
+		weekdayCount[h.Date.Weekday()]++
+	}
+	trailingAvg := 0.0
+	if len(history) > 0 {
+		trailingAvg = total / float64(len(history))
+	}
+
+	now := time.Now()
+	generatedAt := now
+	forecasts := make([]EarningsForecast, horizonDays)
+	for i := 0; i < horizonDays; i++ {
+		date := now.AddDate(0, 0, i+1)
+		weekday := date.Weekday()
+		seasonalFactor := 1.0
+		if trailingAvg > 0 && weekdayCount[weekday] > 0 {
+			weekdayAvg := weekdayTotal[weekday] / float64(weekdayCount[weekday])
+			seasonalFactor = weekdayAvg / trailingAvg
+		}
+		forecasts[i] = EarningsForecast{
+			Device:       device,
+			ForecastDate: time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC),
+			ProjectedPCN: trailingAvg * seasonalFactor,
+			Model:        "trailing-average-seasonal",
+			GeneratedAt:  generatedAt,
+		}
+	}
+	return forecasts
+}
+
+func printForecasts(forecasts []EarningsForecast, format string) {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(forecasts)
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"device", "forecast_date", "projected_pcn", "model"})
+		for _, f := range forecasts {
+			w.Write([]string{f.Device, f.ForecastDate.Format(rewardDateLayout), fmt.Sprintf("%.6f", f.ProjectedPCN), f.Model})
+		}
+		w.Flush()
+	default:
+		fmt.Printf("%-20s %-12s %14s\n", "Device", "Date", "Projected PCN")
+		for _, f := range forecasts {
+			fmt.Printf("%-20s %-12s %14.4f\n", f.Device, f.ForecastDate.Format(rewardDateLayout), f.ProjectedPCN)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	registerSubcommand("freshness", runFreshnessCommand)
+}
+
+// freshnessFlowersKey, freshnessHexesKey, and freshnessRewardsKey are the SyncState keys each
+// entity's last-successful-full-sync timestamp (RFC 3339) is recorded under.
+const (
+	freshnessFlowersKey = "flowers_last_synced_at"
+	freshnessHexesKey   = "hexes_last_synced_at"
+	freshnessRewardsKey = "rewards_last_synced_at"
+)
+
+// freshnessEntities maps each entity this tool syncs to its SyncState freshness key, the set
+// runFreshnessCommand checks and recordFreshness writes into.
+var freshnessEntities = []struct {
+	name string
+	key  string
+}{
+	{"flowers", freshnessFlowersKey},
+	{"hexes", freshnessHexesKey},
+	{"rewards", freshnessRewardsKey},
+}
+
+// freshnessGauges holds the in-process last-synced-at time per entity, for the /metrics
+// endpoint; populated by recordFreshness, so it reflects this run's (or --watch process's) own
+// view without a DB round-trip on every scrape.
+var (
+	freshnessGaugesMu sync.Mutex
+	freshnessGauges   = map[string]time.Time{}
+)
+
+// recordFreshness stamps entity's last-successful-full-sync timestamp, both into SyncState (so
+// the freshness command can check it from a separate process) and into freshnessGauges (so a
+// long-running --watch process's /metrics endpoint reflects it immediately).
+func recordFreshness(db *gorm.DB, key, entity string) error {
+	now := time.Now()
+	freshnessGaugesMu.Lock()
+	freshnessGauges[entity] = now
+	freshnessGaugesMu.Unlock()
+	return setSyncState(db, key, now.Format(time.RFC3339))
+}
+
+// writeFreshnessMetrics appends each in-process freshness gauge to the /metrics response, called
+// from serveMetrics's handler alongside its own counters.
+func writeFreshnessMetrics(w http.ResponseWriter) {
+	freshnessGaugesMu.Lock()
+	defer freshnessGaugesMu.Unlock()
+	for entity, lastSynced := range freshnessGauges {
+		fmt.Fprintf(w, "pollen_freshness_age_seconds{entity=%q} %d\n", entity, int64(time.Since(lastSynced).Seconds()))
+	}
+}
+
+// runFreshnessCommand reports how long it's been since each entity's last successful full sync,
+// exiting ExitRunFailure (and escalating, see escalation.go) if any exceeds --threshold, so a
+// monitoring cron job can page on stale data the same way it would on a failed sync.
+func runFreshnessCommand(args []string) int {
+	fs := flag.NewFlagSet("freshness", flag.ExitOnError)
+	threshold := fs.Duration("threshold", 24*time.Hour, "maximum acceptable age of the last successful full sync per entity")
+	fs.Parse(args)
+
+	db, err := openDB(os.Getenv("PG_URL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db connect failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	stale := false
+	for _, e := range freshnessEntities {
+		raw, err := getSyncState(db, e.key)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "query failed: %v\n", err)
+			return ExitDBFailure
+		}
+		if raw == "" {
+			fmt.Printf("%s: never successfully synced\n", e.name)
+			stale = true
+			continue
+		}
+		lastSynced, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			fmt.Printf("%s: unparseable freshness timestamp %q\n", e.name, raw)
+			stale = true
+			continue
+		}
+		age := time.Since(lastSynced)
+		if age > *threshold {
+			fmt.Printf("%s: stale, last synced %s ago (threshold %s)\n", e.name, age.Round(time.Second), threshold)
+			stale = true
+		} else {
+			fmt.Printf("%s: fresh, last synced %s ago\n", e.name, age.Round(time.Second))
+		}
+	}
+
+	if stale {
+		if err := escalate(db, "pollen data freshness SLO breached"); err != nil {
+			fmt.Fprintf(os.Stderr, "escalation failed: %v\n", err)
+		}
+		return ExitRunFailure
+	}
+	fmt.Println("all entities within freshness threshold")
+	return ExitSuccess
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisURL points the geocode cache at a shared Redis instance instead of the default
+// in-process map, so multiple sync instances covering different regions on different hosts
+// share geocoding results and collectively respect the Nominatim rate limit.
+var redisURL = flag.String("redis-url", "", "Redis URL (redis://...) for a shared geocode cache; empty uses an in-process cache")
+
+// geocodeCacher is the geocode cache's storage backend, either the in-process map (default) or
+// Redis (--redis-url).
+type geocodeCacher interface {
+	Get(hex string) (ReverseGeocode, bool)
+	Set(hex string, g ReverseGeocode)
+}
+
+// geocodeCache is the package-level cache reverseGeocode reads and writes through, set once in
+// runSync via openGeocodeCache.
+var geocodeCache geocodeCacher = newInMemoryGeocodeCache()
+
+// openGeocodeCache returns a Redis-backed cache if --redis-url is set, otherwise the default
+// in-process cache.
+func openGeocodeCache() (geocodeCacher, error) {
+	if *redisURL == "" {
+		return newInMemoryGeocodeCache(), nil
+	}
+	opts, err := redis.ParseURL(*redisURL)
+	if err != nil {
+		return nil, err
+	}
+	return &redisGeocodeCache{client: redis.NewClient(opts)}, nil
+}
+
+// inMemoryGeocodeCache is the default geocodeCacher, a mutex-guarded map local to this process.
+type inMemoryGeocodeCache struct {
+	mu sync.Mutex
+	m  map[string]ReverseGeocode
+}
+
+func newInMemoryGeocodeCache() *inMemoryGeocodeCache {
+	return &inMemoryGeocodeCache{m: map[string]ReverseGeocode{}}
+}
+
+func (c *inMemoryGeocodeCache) Get(hex string) (ReverseGeocode, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	g, ok := c.m[hex]
+	return g, ok
+}
+
+func (c *inMemoryGeocodeCache) Set(hex string, g ReverseGeocode) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m[hex] = g
+}
+
+// redisCacheKeyPrefix namespaces geocode entries in a Redis instance that may be shared with
+// other tools.
+const redisCacheKeyPrefix = "pollen:geocode:"
+
+// redisGeocodeCache is a geocodeCacher backed by Redis, shared across every sync instance
+// pointed at the same Redis URL.
+type redisGeocodeCache struct {
+	client *redis.Client
+}
+
+func (c *redisGeocodeCache) Get(hex string) (ReverseGeocode, bool) {
+	raw, err := c.client.Get(context.Background(), redisCacheKeyPrefix+hex).Bytes()
+	if err != nil {
+		return ReverseGeocode{}, false
+	}
+	var g ReverseGeocode
+	if err := json.Unmarshal(raw, &g); err != nil {
+		return ReverseGeocode{}, false
+	}
+	return g, true
+}
+
+func (c *redisGeocodeCache) Set(hex string, g ReverseGeocode) {
+	raw, err := json.Marshal(g)
+	if err != nil {
+		return
+	}
+	c.client.Set(context.Background(), redisCacheKeyPrefix+hex, raw, 0)
+}
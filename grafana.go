@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+func init() {
+	registerSubcommand("grafana", runGrafanaCommand)
+}
+
+// GrafanaAnnotation is one entry in Grafana's generic Postgres annotations query (time, text,
+// tags), written by writeGrafanaAnnotation whenever something dashboard-worthy happens (today,
+// escalate calls it for every paged incident), so significant events show up as vertical
+// markers on a panel instead of needing to be cross-referenced against logs.
+type GrafanaAnnotation struct {
+	ID    uint `gorm:"primaryKey"`
+	Time  time.Time
+	Title string
+	Text  string
+	Tags  pq.StringArray `gorm:"type:text[]"`
+}
+
+var tableNameGrafanaAnnotation = "pollen_grafana_annotations"
+
+func (a *GrafanaAnnotation) TableName() string {
+	return tableNameGrafanaAnnotation
+}
+
+func init() {
+	models = append(models, GrafanaAnnotation{})
+}
+
+// writeGrafanaAnnotation records one dashboard annotation.
+func writeGrafanaAnnotation(db *gorm.DB, title, text string, tags []string) error {
+	return db.Create(&GrafanaAnnotation{
+		Time:  time.Now(),
+		Title: title,
+		Text:  text,
+		Tags:  pq.StringArray(tags),
+	}).Error
+}
+
+// grafanaViews are the reporting views the Grafana dashboard pack's queries expect: coverage
+// over time (from the per-region sync stats, see regionstats.go), earnings by wallet over time,
+// and sync health.
+var grafanaViews = []string{
+	`CREATE OR REPLACE VIEW pollen_grafana_coverage_over_time AS
+		SELECT r.started_at AS time, rr.region, rr.hexes_fetched, rr.covered, rr.new_flowers
+		FROM pollen_sync_run_regions rr
+		JOIN pollen_sync_runs r ON r.id = rr.sync_run_id
+		ORDER BY r.started_at`,
+	`CREATE OR REPLACE VIEW pollen_grafana_earnings_by_wallet AS
+		SELECT date AS time, wallet, SUM(pcn) AS pcn
+		FROM pollen_rewards
+		GROUP BY date, wallet
+		ORDER BY date`,
+	`CREATE OR REPLACE VIEW pollen_grafana_sync_health AS
+		SELECT started_at AS time, label, result,
+			EXTRACT(EPOCH FROM (finished_at - started_at)) AS duration_seconds
+		FROM pollen_sync_runs
+		ORDER BY started_at`,
+}
+
+// runGrafanaCommand creates the views the Grafana dashboard pack's queries expect. The
+// annotations table migrates the normal way (GrafanaAnnotation is registered in models), so the
+// usual sync/init/migrate schema migration chain must have already run against this database.
+func runGrafanaCommand(args []string) int {
+	db, err := openDB(os.Getenv("PG_URL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db connect failed: %v\n", err)
+		return ExitDBFailure
+	}
+	for _, view := range grafanaViews {
+		if err := db.Exec(view).Error; err != nil {
+			fmt.Fprintf(os.Stderr, "creating view failed: %v\n", err)
+			return ExitDBFailure
+		}
+	}
+	fmt.Println("grafana schema pack installed")
+	return ExitSuccess
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/uber/h3-go/v4"
+)
+
+func init() {
+	registerSubcommand("h3", runH3Command)
+}
+
+// expandRing lets --expand-ring N pull in the neighboring res-5 hexes around every configured
+// hex group, so coverage that creeps just outside a hand-picked boundary isn't silently missed.
+var expandRing = flag.Int("expand-ring", 0, "expand every hex group to include its k-ring neighbors up to this many grid steps (0 disables expansion)")
+
+// runH3Command dispatches `h3 <subcommand>`, so users can derive the correct res-5 input hexes
+// for their area straight from this tool instead of a third-party H3 playground.
+func runH3Command(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: h3 parent|children|kring <h3> <arg>")
+		return ExitRunFailure
+	}
+	switch args[0] {
+	case "parent":
+		return runH3Parent(args[1:])
+	case "children":
+		return runH3Children(args[1:])
+	case "kring":
+		return runH3KRing(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown h3 subcommand %q\n", args[0])
+		return ExitRunFailure
+	}
+}
+
+// parseCell parses a hex string the same way validateHexGroup does, reusing the cell to print
+// it back in canonical lowercase form.
+func parseCell(s string) (h3.Cell, error) {
+	value, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid hex %q: %w", s, err)
+	}
+	cell := h3.Cell(value)
+	if !cell.IsValid() {
+		return 0, fmt.Errorf("invalid hex %q: not a valid H3 cell", s)
+	}
+	return cell, nil
+}
+
+// runH3Parent prints the ancestor of <h3> at the given resolution.
+func runH3Parent(args []string) int {
+	fs := flag.NewFlagSet("h3 parent", flag.ExitOnError)
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "usage: h3 parent <h3> <resolution>")
+		return ExitRunFailure
+	}
+	cell, err := parseCell(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitRunFailure
+	}
+	resolution, err := strconv.Atoi(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid resolution %q: %v\n", fs.Arg(1), err)
+		return ExitRunFailure
+	}
+	fmt.Println(cell.Parent(resolution).String())
+	return ExitSuccess
+}
+
+// runH3Children prints the children of <h3> at the given resolution, one per line.
+func runH3Children(args []string) int {
+	fs := flag.NewFlagSet("h3 children", flag.ExitOnError)
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "usage: h3 children <h3> <resolution>")
+		return ExitRunFailure
+	}
+	cell, err := parseCell(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitRunFailure
+	}
+	resolution, err := strconv.Atoi(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid resolution %q: %v\n", fs.Arg(1), err)
+		return ExitRunFailure
+	}
+	for _, child := range cell.Children(resolution) {
+		fmt.Println(child.String())
+	}
+	return ExitSuccess
+}
+
+// h3GridDistance returns the number of grid steps between two H3 cell strings, or an error if
+// either isn't a valid cell. Used to size flower relocations (see flowerrelocations.go) rather
+// than just recording that a move happened.
+func h3GridDistance(from, to string) (int, error) {
+	fromCell, err := parseCell(from)
+	if err != nil {
+		return 0, err
+	}
+	toCell, err := parseCell(to)
+	if err != nil {
+		return 0, err
+	}
+	return fromCell.GridDistance(toCell), nil
+}
+
+// expandHexGroup replaces hexGroup's comma-separated hexes with the union of their k-rings
+// (each hex and every cell within k grid steps of it, via h3.GridDisk), deduplicated, so
+// --expand-ring lets coverage that creeps just outside a configured boundary still get synced
+// instead of silently falling outside every configured hex group.
+func expandHexGroup(hexGroup string, k int) (string, error) {
+	seen := map[h3.Cell]bool{}
+	var expanded []string
+	for _, hex := range strings.Split(hexGroup, ",") {
+		cell, err := parseCell(hex)
+		if err != nil {
+			return "", err
+		}
+		for _, neighbor := range cell.GridDisk(k) {
+			if seen[neighbor] {
+				continue
+			}
+			seen[neighbor] = true
+			expanded = append(expanded, neighbor.String())
+		}
+	}
+	return strings.Join(expanded, ","), nil
+}
+
+// runH3KRing prints <h3> and every cell within k grid steps of it, one per line.
+func runH3KRing(args []string) int {
+	fs := flag.NewFlagSet("h3 kring", flag.ExitOnError)
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "usage: h3 kring <h3> <k>")
+		return ExitRunFailure
+	}
+	cell, err := parseCell(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitRunFailure
+	}
+	k, err := strconv.Atoi(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid k %q: %v\n", fs.Arg(1), err)
+		return ExitRunFailure
+	}
+	for _, neighbor := range cell.GridDisk(k) {
+		fmt.Println(neighbor.String())
+	}
+	return ExitSuccess
+}
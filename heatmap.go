@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"strconv"
+	"time"
+
+	"github.com/uber/h3-go/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// heatmap expands every covered hex we see (a region's res-5 hexes, and the finer-grained
+// coverage lists recorded against individual hexes/flowers) into resolution-8 cells in a
+// dedicated table, so a map can render a smooth heatmap at neighborhood zoom without the client
+// having to know which resolution each source hex came in at.
+var heatmap = flag.Bool("heatmap", false, "expand covered hexes into res-8 cells in pollen_heatmap_cells, for heatmap rendering")
+
+const heatmapResolution = 8
+
+// HeatmapCell is one resolution-8 cell that overlaps at least one covered hex we've seen.
+type HeatmapCell struct {
+	CellID    string    `gorm:"primaryKey"`
+	UpdatedAt time.Time `gorm:"not null;default:current_timestamp"`
+}
+
+var tableNameHeatmapCell = "pollen_heatmap_cells"
+
+func (h *HeatmapCell) TableName() string {
+	return tableNameHeatmapCell
+}
+
+func init() {
+	models = append(models, HeatmapCell{})
+}
+
+// res8Cells returns the resolution-8 cells hex overlaps: itself if it's already res-8, its
+// single res-8 ancestor if it's finer, or all of its res-8 descendants if it's coarser (e.g. a
+// region's res-5 hexes expand to many res-8 cells).
+func res8Cells(hex string) ([]string, error) {
+	value, err := strconv.ParseInt(hex, 16, 64)
+	if err != nil {
+		return nil, err
+	}
+	cell := h3.Cell(value)
+	if !cell.IsValid() {
+		return nil, err
+	}
+	switch res := cell.Resolution(); {
+	case res == heatmapResolution:
+		return []string{cell.String()}, nil
+	case res > heatmapResolution:
+		return []string{cell.Parent(heatmapResolution).String()}, nil
+	default:
+		children := cell.Children(heatmapResolution)
+		cells := make([]string, len(children))
+		for i, c := range children {
+			cells[i] = c.String()
+		}
+		return cells, nil
+	}
+}
+
+// writeHeatmapCells expands every hex in hexes to res-8 and upserts the result, a no-op when
+// --heatmap isn't set or hexes is empty. Invalid hexes are skipped rather than failing the run,
+// since coverage lists occasionally contain the odd malformed entry from the upstream API.
+func writeHeatmapCells(db *gorm.DB, hexes []string) error {
+	if !*heatmap || len(hexes) == 0 {
+		return nil
+	}
+	seen := map[string]bool{}
+	var rows []HeatmapCell
+	for _, hex := range hexes {
+		cells, err := res8Cells(hex)
+		if err != nil {
+			continue
+		}
+		for _, c := range cells {
+			if seen[c] {
+				continue
+			}
+			seen[c] = true
+			rows = append(rows, HeatmapCell{CellID: c})
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cell_id"}},
+		DoUpdates: []clause.Assignment{{Column: clause.Column{Name: "updated_at"}, Value: time.Now()}},
+	}).CreateInBatches(&rows, 200).Error
+}
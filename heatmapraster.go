@@ -0,0 +1,184 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+
+	"gorm.io/gorm"
+)
+
+// heatmapPoint is one weighted sample fed into rasterizeHeatmap: a location and the metric
+// value ("attach" counts at a hex centerpoint, or "reward" PCN totals at a flower's location).
+type heatmapPoint struct {
+	Lat, Lng float64
+	Value    float64
+}
+
+// runReportHeatmap writes a PNG raster heatmap of attach counts or reward density over a
+// region, for RF planners who want to overlay Pollen activity on their own mapping tools.
+//
+// This intentionally produces a plain PNG rather than a georeferenced GeoTIFF: a correct
+// GeoTIFF writer needs a dedicated encoding library, which is more than this command's use
+// case (eyeballing hot spots) justifies pulling in. The bounds are printed alongside the PNG so
+// it can still be aligned manually in a GIS tool.
+func runReportHeatmap(args []string) int {
+	fs := flag.NewFlagSet("report heatmap", flag.ExitOnError)
+	metric := fs.String("metric", "attach", "metric to plot: attach (hex attach counts) or reward (flower PCN totals)")
+	region := fs.String("region", "", "only include hexes/flowers whose region (H3HexTop) matches this value")
+	out := fs.String("out", "heatmap.png", "path to write the PNG heatmap to")
+	width := fs.Int("width", 800, "raster width in pixels")
+	height := fs.Int("height", 800, "raster height in pixels")
+	fs.Parse(args)
+
+	db, err := openDB(os.Getenv("PG_URL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db connect failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	var points []heatmapPoint
+	switch *metric {
+	case "attach":
+		points, err = loadAttachPoints(db, *region)
+	case "reward":
+		points, err = loadRewardPoints(db, *region)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown metric %q, want attach or reward\n", *metric)
+		return ExitRunFailure
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "query failed: %v\n", err)
+		return ExitDBFailure
+	}
+	if len(points) == 0 {
+		fmt.Println("no points with coordinates found, nothing to export")
+		return ExitSuccess
+	}
+
+	img, minLat, minLng, maxLat, maxLng := rasterizeHeatmap(points, *width, *height)
+
+	f, err := os.Create(*out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "creating %s failed: %v\n", *out, err)
+		return ExitRunFailure
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		fmt.Fprintf(os.Stderr, "encoding %s failed: %v\n", *out, err)
+		return ExitRunFailure
+	}
+
+	fmt.Printf("wrote %s (bounds %f,%f,%f,%f)\n", *out, minLng, minLat, maxLng, maxLat)
+	return ExitSuccess
+}
+
+// loadAttachPoints returns one heatmapPoint per hex with a positive attach count, optionally
+// scoped to region (H3HexTop).
+func loadAttachPoints(db *gorm.DB, region string) ([]heatmapPoint, error) {
+	q := db.Select("lat", "lng", "attach").Where("attach > 0 AND (lat <> 0 OR lng <> 0)")
+	if region != "" {
+		q = q.Where("h3_hex_top = ?", region)
+	}
+	var hexes []Hex
+	if err := q.Find(&hexes).Error; err != nil {
+		return nil, err
+	}
+	points := make([]heatmapPoint, 0, len(hexes))
+	for _, hex := range hexes {
+		points = append(points, heatmapPoint{Lat: hex.Lat, Lng: hex.Lng, Value: float64(hex.Attach)})
+	}
+	return points, nil
+}
+
+// loadRewardPoints returns one heatmapPoint per flower with positive total PCN rewards,
+// optionally scoped to region (H3HexTop, matched via the flower's own hex).
+func loadRewardPoints(db *gorm.DB, region string) ([]heatmapPoint, error) {
+	q := db.Table(tableNameFlower).Select("lat", "lng", "flower_rewards").
+		Where("flower_rewards > 0 AND (lat <> 0 OR lng <> 0)")
+	if region != "" {
+		q = q.Where("h3_hex IN (?)", db.Table(tableNameHex).Select("id").Where("h3_hex_top = ?", region))
+	}
+	var flowers []Flower
+	if err := q.Find(&flowers).Error; err != nil {
+		return nil, err
+	}
+	points := make([]heatmapPoint, 0, len(flowers))
+	for _, f := range flowers {
+		points = append(points, heatmapPoint{Lat: f.Lat, Lng: f.Lng, Value: f.FlowerRewards})
+	}
+	return points, nil
+}
+
+// rasterizeHeatmap bins points into a width x height grid over their bounding box, accumulating
+// Value per cell, then colors each cell along a blue (low) to red (high) ramp scaled to the
+// grid's own max. It returns the image plus the lat/lng bounds it covers.
+func rasterizeHeatmap(points []heatmapPoint, width, height int) (image.Image, float64, float64, float64, float64) {
+	minLat, minLng := points[0].Lat, points[0].Lng
+	maxLat, maxLng := points[0].Lat, points[0].Lng
+	for _, p := range points {
+		minLat, maxLat = math.Min(minLat, p.Lat), math.Max(maxLat, p.Lat)
+		minLng, maxLng = math.Min(minLng, p.Lng), math.Max(maxLng, p.Lng)
+	}
+	// Pad degenerate (single-point or single-line) bounds so every point still lands in-grid.
+	if minLat == maxLat {
+		minLat, maxLat = minLat-0.01, maxLat+0.01
+	}
+	if minLng == maxLng {
+		minLng, maxLng = minLng-0.01, maxLng+0.01
+	}
+
+	grid := make([]float64, width*height)
+	for _, p := range points {
+		x := int((p.Lng - minLng) / (maxLng - minLng) * float64(width))
+		y := int((maxLat - p.Lat) / (maxLat - minLat) * float64(height))
+		x = clampInt(x, 0, width-1)
+		y = clampInt(y, 0, height-1)
+		grid[y*width+x] += p.Value
+	}
+
+	maxValue := 0.0
+	for _, v := range grid {
+		maxValue = math.Max(maxValue, v)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, heatColor(grid[y*width+x], maxValue))
+		}
+	}
+	return img, minLat, minLng, maxLat, maxLng
+}
+
+// heatColor maps value (0..max) onto a blue-to-red ramp, transparent at zero so empty cells
+// don't obscure whatever basemap the PNG is overlaid on.
+func heatColor(value, max float64) color.Color {
+	if value <= 0 || max <= 0 {
+		return color.RGBA{0, 0, 0, 0}
+	}
+	t := value / max
+	if t > 1 {
+		t = 1
+	}
+	return color.RGBA{
+		R: uint8(255 * t),
+		G: uint8(64 * (1 - t)),
+		B: uint8(255 * (1 - t)),
+		A: uint8(80 + 175*t),
+	}
+}
+
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
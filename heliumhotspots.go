@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/uber/h3-go/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func init() {
+	registerSubcommand("helium", runHeliumCommand)
+	models = append(models, HeliumHotspot{})
+}
+
+// heliumResolution is the H3 resolution Helium hotspots are bucketed to for comparison against
+// our own hexes, matching heatmap.go's res8Cells convention for "the" comparable resolution.
+const heliumResolution = 8
+
+// HeliumHotspot is one hotspot from a Helium public hotspot dataset export, imported via
+// `helium import` so `helium compare` can show where Pollen coverage overlaps or fills gaps
+// relative to Helium.
+type HeliumHotspot struct {
+	Address    string `gorm:"primaryKey"`
+	Name       string
+	City       string
+	State      string
+	Lat        float64
+	Lng        float64
+	H3Hex      string    `gorm:"index"`
+	ImportedAt time.Time `gorm:"not null;default:current_timestamp"`
+}
+
+var tableNameHeliumHotspot = "pollen_helium_hotspots"
+
+func (h *HeliumHotspot) TableName() string {
+	return tableNameHeliumHotspot
+}
+
+// runHeliumCommand dispatches `helium <subcommand>`.
+func runHeliumCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: helium import|compare ...")
+		return ExitRunFailure
+	}
+	switch args[0] {
+	case "import":
+		return runHeliumImport(args[1:])
+	case "compare":
+		return runHeliumCompare(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown helium subcommand %q\n", args[0])
+		return ExitRunFailure
+	}
+}
+
+// runHeliumImport loads a Helium public hotspot dataset CSV (expects at least address, lat, lng
+// columns, with optional name/city/state) and upserts it into pollen_helium_hotspots, deriving
+// each hotspot's H3 cell at heliumResolution for later comparison.
+func runHeliumImport(args []string) int {
+	fs := flag.NewFlagSet("helium import", flag.ExitOnError)
+	file := fs.String("file", "", "path to a Helium hotspot CSV export")
+	fs.Parse(args)
+	if *file == "" {
+		fmt.Fprintln(os.Stderr, "usage: helium import --file hotspots.csv")
+		return ExitRunFailure
+	}
+
+	hotspots, err := readHeliumHotspots(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read %s: %v\n", *file, err)
+		return ExitRunFailure
+	}
+	if len(hotspots) == 0 {
+		fmt.Println("no hotspots to import")
+		return ExitSuccess
+	}
+
+	db, err := openDB(os.Getenv("PG_URL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db connect failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	err = withDBRetry(func() error {
+		return db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "address"}},
+			UpdateAll: true,
+		}).CreateInBatches(&hotspots, 200).Error
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import failed: %v\n", err)
+		return ExitDBFailure
+	}
+	fmt.Printf("imported %d Helium hotspots\n", len(hotspots))
+	return ExitSuccess
+}
+
+// readHeliumHotspots parses a Helium hotspot CSV (header row = column names, case-insensitive
+// address/name/lat/lng/city/state) into HeliumHotspot rows with H3Hex derived from lat/lng.
+func readHeliumHotspots(path string) ([]HeliumHotspot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	col := map[string]int{}
+	for i, name := range records[0] {
+		col[strings.ToLower(name)] = i
+	}
+	get := func(record []string, name string) string {
+		i, ok := col[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+
+	hotspots := make([]HeliumHotspot, 0, len(records)-1)
+	for _, record := range records[1:] {
+		lat, err := strconv.ParseFloat(get(record, "lat"), 64)
+		if err != nil {
+			continue
+		}
+		lng, err := strconv.ParseFloat(get(record, "lng"), 64)
+		if err != nil {
+			continue
+		}
+		hotspots = append(hotspots, HeliumHotspot{
+			Address: get(record, "address"),
+			Name:    get(record, "name"),
+			City:    get(record, "city"),
+			State:   get(record, "state"),
+			Lat:     lat,
+			Lng:     lng,
+			H3Hex:   h3.LatLngToCell(h3.LatLng{Lat: lat, Lng: lng}, heliumResolution).String(),
+		})
+	}
+	return hotspots, nil
+}
+
+// heliumComparisonRow is one region/city's Pollen-vs-Helium hex overlap counts.
+type heliumComparisonRow struct {
+	City         string `json:"city"`
+	PollenHexes  int    `json:"pollen_hexes"`
+	HeliumHexes  int    `json:"helium_hexes"`
+	OverlapHexes int    `json:"overlap_hexes"`
+	PollenOnly   int    `json:"pollen_only"`
+	HeliumOnly   int    `json:"helium_only"`
+}
+
+// runHeliumCompare reports, per Helium hotspot city, how many res-8 cells have Pollen coverage
+// only, Helium coverage only, or both, so a fleet operator can see where Pollen coverage fills
+// gaps Helium doesn't (or vice versa). Only the Helium side is grouped by city: each row's
+// PollenOnly and PollenHexes are counted against every covered Pollen cell in --region, not just
+// that city's, so PollenHexes is the same total in every row.
+func runHeliumCompare(args []string) int {
+	fs := flag.NewFlagSet("helium compare", flag.ExitOnError)
+	region := fs.String("region", "", "only include hexes whose region (H3HexTop) matches this value")
+	format := fs.String("format", "table", "output format: table or json")
+	fs.Parse(args)
+
+	db, err := openDB(os.Getenv("PG_URL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db connect failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	pollenCells, err := pollenHeliumResCells(db, *region)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "query failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	var hotspots []HeliumHotspot
+	if err := db.Find(&hotspots).Error; err != nil {
+		fmt.Fprintf(os.Stderr, "query failed: %v\n", err)
+		return ExitDBFailure
+	}
+	heliumCellsByCity := map[string]map[string]bool{}
+	for _, h := range hotspots {
+		city := h.City
+		if city == "" {
+			city = "unknown"
+		}
+		if heliumCellsByCity[city] == nil {
+			heliumCellsByCity[city] = map[string]bool{}
+		}
+		heliumCellsByCity[city][h.H3Hex] = true
+	}
+
+	var rows []heliumComparisonRow
+	for city, heliumCells := range heliumCellsByCity {
+		row := heliumComparisonRow{City: city, HeliumHexes: len(heliumCells)}
+		for cell := range heliumCells {
+			if pollenCells[cell] {
+				row.OverlapHexes++
+			} else {
+				row.HeliumOnly++
+			}
+		}
+		for cell := range pollenCells {
+			if !heliumCells[cell] {
+				row.PollenOnly++
+			}
+		}
+		row.PollenHexes = row.OverlapHexes + row.PollenOnly
+		rows = append(rows, row)
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(rows)
+	default:
+		fmt.Printf("%-24s %12s %12s %12s %12s %12s\n", "City", "Pollen", "Helium", "Overlap", "PollenOnly", "HeliumOnly")
+		for _, r := range rows {
+			fmt.Printf("%-24s %12d %12d %12d %12d %12d\n", r.City, r.PollenHexes, r.HeliumHexes, r.OverlapHexes, r.PollenOnly, r.HeliumOnly)
+		}
+	}
+	return ExitSuccess
+}
+
+// pollenHeliumResCells returns the set of res-8 cells Pollen covers, optionally scoped to
+// region (H3HexTop), expanding coarser/finer hex ids the same way res8Cells does.
+func pollenHeliumResCells(db *gorm.DB, region string) (map[string]bool, error) {
+	q := db.Select("id").Where("covered > 0")
+	if region != "" {
+		q = q.Where("h3_hex_top = ?", region)
+	}
+	var hexes []Hex
+	if err := q.Find(&hexes).Error; err != nil {
+		return nil, err
+	}
+	cells := map[string]bool{}
+	for _, hex := range hexes {
+		expanded, err := res8Cells(hex.ID)
+		if err != nil {
+			continue
+		}
+		for _, c := range expanded {
+			cells[c] = true
+		}
+	}
+	return cells, nil
+}
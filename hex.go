@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func init() {
+	registerSubcommand("hex", runHexCommand)
+}
+
+// runHexCommand dispatches `hex <subcommand>`.
+func runHexCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: hex show <h3> [--refresh]")
+		return ExitRunFailure
+	}
+	switch args[0] {
+	case "show":
+		return runHexShow(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown hex subcommand %q\n", args[0])
+		return ExitRunFailure
+	}
+}
+
+// runHexShow prints the stored details for a single hex, optionally refreshing it from the
+// Pollen API first.
+func runHexShow(args []string) int {
+	fs := flag.NewFlagSet("hex show", flag.ExitOnError)
+	refresh := fs.Bool("refresh", false, "fetch the latest details for this hex from the Pollen API before printing")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: hex show <h3> [--refresh]")
+		return ExitRunFailure
+	}
+	hexID := fs.Arg(0)
+
+	db, err := openDB(os.Getenv("PG_URL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db connect failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	if *refresh {
+		list, err := getAllHexes(hexID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "refresh failed: %v\n", err)
+			return ExitRunFailure
+		}
+		for _, h := range list {
+			if err := upsertHex(db, h); err != nil {
+				fmt.Fprintf(os.Stderr, "refresh failed: %v\n", err)
+				return ExitRunFailure
+			}
+		}
+	}
+
+	var hex Hex
+	if err := db.Table(tableNameHex).Where("id = ?", hexID).Take(&hex).Error; err != nil {
+		fmt.Fprintf(os.Stderr, "hex %s not found: %v\n", hexID, err)
+		return ExitRunFailure
+	}
+	fmt.Printf("Hex:      %s\n", hex.ID)
+	fmt.Printf("Address:  %s\n", coalesce(hex.Address, "(unknown)"))
+	fmt.Printf("Location: %s\n", coalesce(hex.City, hex.Town, hex.County, hex.Suburb))
+	fmt.Printf("Covered:  %d   Flowers: %d   Attach: %d\n", hex.Covered, hex.FlowerCount, hex.Attach)
+	fmt.Printf("Bounty:   %s (reward=%.2f, time=%s)\n", coalesce(hex.Bounty, "none"), hex.BountyReward, hex.BountyTime)
+	fmt.Printf("Rewards:  daily=%d lootbox=%d\n", hex.DailyReward, hex.LootBoxReward)
+	fmt.Printf("Flowers in hex: %v\n", []string(hex.Flowers))
+	return ExitSuccess
+}
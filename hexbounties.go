@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// HexBounty is an append-only event log of a hex's bounty state changing, since Hex itself only
+// ever holds the latest bounty/bounty_reward/bounty_time and each upsert overwrites a completed
+// or expired bounty without a trace.
+type HexBounty struct {
+	ID           uint   `gorm:"primaryKey"`
+	HexID        string `gorm:"index"`
+	Bounty       string
+	BountyReward float64
+	BountyTime   string
+	RecordedAt   time.Time
+}
+
+var tableNameHexBounty = "pollen_hex_bounties"
+
+func (b *HexBounty) TableName() string {
+	return tableNameHexBounty
+}
+
+func init() {
+	models = append(models, HexBounty{})
+}
+
+// recordHexBounty inserts a pollen_hex_bounties event when hexID's bounty state differs from
+// what's currently stored, alerting (see bountyalerts.go) if a new bounty just appeared on a hex
+// in a configured region. A hex seen for the first time isn't a "change" and records/alerts
+// nothing.
+func recordHexBounty(db *gorm.DB, hexID, region, bounty string, bountyReward float64, bountyTime string) error {
+	var previous Hex
+	err := db.Select("bounty", "bounty_reward", "bounty_time").Where("id = ?", hexID).Take(&previous).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if previous.Bounty == bounty && previous.BountyReward == bountyReward && previous.BountyTime == bountyTime {
+		return nil
+	}
+	if err := alertNewBounty(hexID, region, previous.Bounty, bounty); err != nil {
+		return err
+	}
+	return db.Create(&HexBounty{HexID: hexID, Bounty: bounty, BountyReward: bountyReward, BountyTime: bountyTime, RecordedAt: time.Now()}).Error
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"io"
+	"os"
+	"strings"
+)
+
+// hexesFile lets hex groups come from a file (or stdin, with "-") instead of a 33-hex
+// comma-separated shell argument that's unmanageable in shell history and crontabs.
+var hexesFile = flag.String("hexes-file", "", "read hex groups from this file (one per line, '#' comments allowed), or '-' for stdin")
+
+// readHexGroupsFile reads one hex group per non-comment, non-blank line from path (or stdin if
+// path is "-"), returning them as a slice ready to append to the positional hex group args.
+func readHexGroupsFile(path string) ([]string, error) {
+	var r io.Reader
+	if path == "-" {
+		r = os.Stdin
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var groups []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			continue
+		}
+		groups = append(groups, line)
+	}
+	return groups, scanner.Err()
+}
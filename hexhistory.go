@@ -0,0 +1,49 @@
+package main
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// HexHistory is an append-only log of a hex's signal quality at each sync, since Hex itself
+// only ever holds the latest values: deployment planning needs to see whether a hex's signal
+// strength or ping is trending, not just where it stands right now.
+type HexHistory struct {
+	ID             uint   `gorm:"primaryKey"`
+	HexID          string `gorm:"index"`
+	SignalStrength int
+	Ping           float64
+	LastCovered    string
+	LastPollenDrop string
+	Device         pq.StringArray `gorm:"type:text[]"`
+	RecordedAt     time.Time
+}
+
+var tableNameHexHistory = "pollen_hex_history"
+
+func (h *HexHistory) TableName() string {
+	return tableNameHexHistory
+}
+
+func init() {
+	models = append(models, HexHistory{})
+}
+
+// writeHexHistory appends a row recording hex's current signal-quality details, a no-op under
+// --slim since it's exactly the kind of heavy, rarely-queried history that mode exists to skip.
+func writeHexHistory(db *gorm.DB, hex HexListItem, details HexItem) error {
+	if *slim {
+		return nil
+	}
+	return db.Create(&HexHistory{
+		HexID:          hex.ID,
+		SignalStrength: int(details.Hex.SignalStrength),
+		Ping:           float64(details.Hex.Ping),
+		LastCovered:    details.Hex.LastCovered,
+		LastPollenDrop: details.Hex.LastPollenDrop,
+		Device:         pq.StringArray(details.Hex.Device),
+		RecordedAt:     time.Now(),
+	}).Error
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LootBoxEvent is an append-only event log of a hex's loot_box_reward value changing, since Hex
+// itself only ever holds the latest integer and lootbox drops are time-limited incentives people
+// want to analyze historically.
+type LootBoxEvent struct {
+	ID            uint   `gorm:"primaryKey"`
+	HexID         string `gorm:"index"`
+	LootBoxReward int
+	RecordedAt    time.Time
+}
+
+var tableNameLootBoxEvent = "pollen_lootbox_events"
+
+func (e *LootBoxEvent) TableName() string {
+	return tableNameLootBoxEvent
+}
+
+func init() {
+	models = append(models, LootBoxEvent{})
+}
+
+// recordLootBoxEvent inserts a pollen_lootbox_events event when hexID's loot_box_reward differs
+// from what's currently stored. A hex seen for the first time isn't a "change" and records
+// nothing.
+func recordLootBoxEvent(db *gorm.DB, hexID string, lootBoxReward int) error {
+	var previous Hex
+	err := db.Select("loot_box_reward").Where("id = ?", hexID).Take(&previous).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if previous.LootBoxReward == lootBoxReward {
+		return nil
+	}
+	return db.Create(&LootBoxEvent{HexID: hexID, LootBoxReward: lootBoxReward, RecordedAt: time.Now()}).Error
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cacheDir and cacheTTL configure an on-disk cache for hex-detail and flower API responses,
+// keyed by URL, so repeated runs within a short window (or crash-restart loops) don't re-spend
+// the API budget re-fetching data that's still fresh.
+var (
+	cacheDir = flag.String("cache-dir", "", "directory for on-disk HTTP response cache (hex details, flowers); empty disables caching")
+	cacheTTL = flag.Duration("cache-ttl", 6*time.Hour, "how long a cached response stays valid")
+)
+
+// cacheKeyPath returns the on-disk path a URL's cached response would live at.
+func cacheKeyPath(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(*cacheDir, hex.EncodeToString(sum[:])+".json")
+}
+
+// cacheGet returns the cached bytes for url if --cache-dir is set and a fresh entry exists.
+func cacheGet(url string) ([]byte, bool) {
+	if *cacheDir == "" {
+		return nil, false
+	}
+	path := cacheKeyPath(url)
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > *cacheTTL {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// cachePut writes v's JSON encoding to the cache for url, a no-op if --cache-dir isn't set.
+func cachePut(url string, v interface{}) {
+	if *cacheDir == "" {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(*cacheDir, 0o755); err != nil {
+		return
+	}
+	os.WriteFile(cacheKeyPath(url), data, 0o644)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	registerSubcommand("import", runImportCommand)
+}
+
+// importTables maps the --table flag value to the underlying table name.
+var importTables = map[string]string{
+	"flowers": tableNameFlower,
+	"hexes":   tableNameHex,
+	"rewards": tableNameReward,
+}
+
+// runImportCommand loads a previously exported CSV/JSONL dump into one of the sync tables,
+// upserting on id, so a new deployment can be bootstrapped from an archive instead of
+// re-crawling the API from scratch.
+func runImportCommand(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	table := fs.String("table", "", "table to import into: flowers, hexes, or rewards")
+	file := fs.String("file", "", "path to a .csv or .jsonl dump (one row/object per line)")
+	fs.Parse(args)
+	if *table == "" || *file == "" {
+		fmt.Fprintln(os.Stderr, "usage: import --table flowers|hexes|rewards --file dump.csv|dump.jsonl")
+		return ExitRunFailure
+	}
+	tableName, ok := importTables[*table]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown table %q (expected flowers, hexes, or rewards)\n", *table)
+		return ExitRunFailure
+	}
+
+	rows, err := readImportRows(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read %s: %v\n", *file, err)
+		return ExitRunFailure
+	}
+	if len(rows) == 0 {
+		fmt.Println("no rows to import")
+		return ExitSuccess
+	}
+
+	db, err := openDB(os.Getenv("PG_URL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db connect failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	err = withDBRetry(func() error {
+		return db.Transaction(func(tx *gorm.DB) error {
+			return tx.Table(tableName).Clauses(upsertClause).CreateInBatches(rows, 200).Error
+		})
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import failed: %v\n", err)
+		return ExitDBFailure
+	}
+	fmt.Printf("imported %d rows into %s\n", len(rows), tableName)
+	return ExitSuccess
+}
+
+// readImportRows reads a CSV (header row = column names) or JSONL (one JSON object per line)
+// dump into a slice of column maps suitable for a gorm upsert.
+func readImportRows(path string) ([]map[string]interface{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.HasSuffix(path, ".jsonl") || strings.HasSuffix(path, ".json") {
+		var rows []map[string]interface{}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			var row map[string]interface{}
+			if err := json.Unmarshal([]byte(line), &row); err != nil {
+				return nil, err
+			}
+			rows = append(rows, row)
+		}
+		return rows, scanner.Err()
+	}
+
+	r := csv.NewReader(f)
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	header := records[0]
+	rows := make([]map[string]interface{}, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]interface{}, len(header))
+		for i, col := range header {
+			if i < len(record) {
+				row[col] = record[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/uber/h3-go/v4"
+)
+
+func init() {
+	registerSubcommand("init", runInitCommand)
+}
+
+// runInitCommand interactively asks a new user for their Postgres URL, region (by city name),
+// and sync schedule, writes a .env and --config profile from the answers, runs migrations, and
+// performs a small validation sync against the derived region, so getting this tool running for
+// the first time doesn't require reading the rest of the flags first.
+func runInitCommand(args []string) int {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	envFile := fs.String("env-file", ".env", "path to write PG_URL to")
+	configFile := fs.String("config-file", "pollen.config.json", "path to write the --config profile to")
+	fs.Parse(args)
+
+	stdin := bufio.NewReader(os.Stdin)
+	pgURL := prompt(stdin, "Postgres URL", os.Getenv("PG_URL"))
+	city := prompt(stdin, "Region (city name, e.g. \"Austin, TX\"; leave blank to add hexes later)", "")
+	schedule := prompt(stdin, "Sync schedule (cron expression, e.g. \"0 * * * *\")", "0 * * * *")
+
+	var region string
+	if city != "" {
+		lat, lng, err := geocodeCityName(city)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "geocoding %q failed: %v\n", city, err)
+			return ExitRunFailure
+		}
+		region = h3.LatLngToCell(h3.LatLng{Lat: lat, Lng: lng}, pollenHexResolution).String()
+		fmt.Printf("resolved %q to hex %s\n", city, region)
+	}
+
+	if err := godotenv.Write(map[string]string{"PG_URL": pgURL}, *envFile); err != nil {
+		fmt.Fprintf(os.Stderr, "writing %s failed: %v\n", *envFile, err)
+		return ExitRunFailure
+	}
+	cfg := config{Profiles: map[string]profile{"default": {PGURL: pgURL, Region: region}}}
+	raw, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "encoding %s failed: %v\n", *configFile, err)
+		return ExitRunFailure
+	}
+	if err := os.WriteFile(*configFile, raw, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "writing %s failed: %v\n", *configFile, err)
+		return ExitRunFailure
+	}
+	fmt.Printf("wrote %s and %s\n", *envFile, *configFile)
+	fmt.Printf("suggested crontab entry: %s cd %s && ./pollen --config %s --profile default\n", schedule, mustGetwd(), *configFile)
+
+	db, err := openDB(pgURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db connect failed: %v\n", err)
+		return ExitDBFailure
+	}
+	for _, model := range models {
+		if err := db.AutoMigrate(&model); err != nil {
+			fmt.Fprintf(os.Stderr, "migration failed: %v\n", err)
+			return ExitDBFailure
+		}
+	}
+	for _, idx := range indexes {
+		if err := db.Exec(idx).Error; err != nil {
+			fmt.Fprintf(os.Stderr, "migration failed: %v\n", err)
+			return ExitDBFailure
+		}
+	}
+	if err := applyCustomIndexes(db); err != nil {
+		fmt.Fprintf(os.Stderr, "migration failed: %v\n", err)
+		return ExitDBFailure
+	}
+	if err := applySlimSchema(db); err != nil {
+		fmt.Fprintf(os.Stderr, "migration failed: %v\n", err)
+		return ExitDBFailure
+	}
+	if err := applyRewardCompositeKey(db); err != nil {
+		fmt.Fprintf(os.Stderr, "migration failed: %v\n", err)
+		return ExitDBFailure
+	}
+	if err := applyJSONBArraySchema(db); err != nil {
+		fmt.Fprintf(os.Stderr, "migration failed: %v\n", err)
+		return ExitDBFailure
+	}
+	if err := applyStrictSchema(db); err != nil {
+		fmt.Fprintf(os.Stderr, "migration failed: %v\n", err)
+		return ExitDBFailure
+	}
+	fmt.Println("schema migrated")
+
+	fmt.Println("running a small validation sync...")
+	if err := syncFlowers(db); err != nil {
+		fmt.Fprintf(os.Stderr, "validation sync failed: %v\n", err)
+		return ExitRunFailure
+	}
+	if region != "" {
+		if _, err := syncHexes(db, region); err != nil {
+			fmt.Fprintf(os.Stderr, "validation sync failed: %v\n", err)
+			return ExitRunFailure
+		}
+	}
+	fmt.Println("validation sync succeeded, init complete")
+	return ExitSuccess
+}
+
+// prompt reads a line from stdin after printing label and, if set, a default value, returning
+// the trimmed input or the default if the user just pressed enter.
+func prompt(stdin *bufio.Reader, label, defaultValue string) string {
+	if defaultValue != "" {
+		fmt.Printf("%s [%s]: ", label, defaultValue)
+	} else {
+		fmt.Printf("%s: ", label)
+	}
+	line, _ := stdin.ReadString('\n')
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return defaultValue
+	}
+	return line
+}
+
+// geocodeCityName forward-geocodes a free-text place name to a lat/lng via Nominatim's /search
+// endpoint, the counterpart to reverseGeocode's /reverse lookup in main.go.
+func geocodeCityName(city string) (lat, lng float64, err error) {
+	api := fmt.Sprintf("https://nominatim.openstreetmap.org/search?q=%s&format=json&limit=1", url.QueryEscape(city))
+	cli := http.Client{Timeout: *httpTimeout}
+	req, err := http.NewRequest(http.MethodGet, api, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	req.Header.Set("user-agent", osmUA)
+	res, err := doHTTP(&cli, req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer res.Body.Close()
+	var results []struct {
+		Lat string `json:"lat"`
+		Lon string `json:"lon"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&results); err != nil {
+		return 0, 0, err
+	}
+	if len(results) == 0 {
+		return 0, 0, fmt.Errorf("no results for %q", city)
+	}
+	if _, err := fmt.Sscanf(results[0].Lat, "%f", &lat); err != nil {
+		return 0, 0, fmt.Errorf("invalid lat %q: %w", results[0].Lat, err)
+	}
+	if _, err := fmt.Sscanf(results[0].Lon, "%f", &lng); err != nil {
+		return 0, 0, fmt.Errorf("invalid lon %q: %w", results[0].Lon, err)
+	}
+	return lat, lng, nil
+}
+
+// mustGetwd returns the current working directory, or "." if it can't be determined, for the
+// suggested crontab line printed by runInitCommand.
+func mustGetwd() string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "."
+	}
+	return wd
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// jsonbArrays, when set, stores Flower's bee/hex list columns and Reward.Coverage as jsonb
+// (with a GIN index for containment queries) instead of Postgres text[] arrays. Several BI
+// tools and ORMs outside this codebase handle jsonb more readily than text[], and jsonb's
+// containment operators are just as indexable via GIN as array membership is. It has no effect
+// on columns --slim has already dropped.
+var jsonbArrays = flag.Bool("jsonb-arrays", false, "store Flower/Reward array fields as jsonb with a GIN index instead of text[]")
+
+// flexArray is a []string that writes as a Postgres array literal by default, or as a JSON
+// array when --jsonb-arrays is set, so the same Go struct field works against either column
+// type without threading the flag through every call site that builds a Flower or Reward.
+type flexArray []string
+
+func (a flexArray) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+	if *jsonbArrays {
+		return json.Marshal([]string(a))
+	}
+	return pq.StringArray(a).Value()
+}
+
+func (a *flexArray) Scan(src interface{}) error {
+	if src == nil {
+		*a = nil
+		return nil
+	}
+	if b, ok := src.([]byte); ok && len(b) > 0 && b[0] == '[' {
+		var out []string
+		if err := json.Unmarshal(b, &out); err != nil {
+			return err
+		}
+		*a = flexArray(out)
+		return nil
+	}
+	var arr pq.StringArray
+	if err := arr.Scan(src); err != nil {
+		return err
+	}
+	*a = flexArray(arr)
+	return nil
+}
+
+// jsonbArrayColumns is the subset of slimColumns that hold Postgres arrays rather than plain
+// text (bees_seen is already a stringified-JSON text column, valid as-is under either type, so
+// it needs no conversion). Reused from slim.go rather than redeclared, since --slim and
+// --jsonb-arrays target the same "heavy blob" columns for opposite reasons.
+var jsonbArrayColumns = func() []slimColumn {
+	var cols []slimColumn
+	for _, c := range slimColumns {
+		if c.column != "bees_seen" {
+			cols = append(cols, c)
+		}
+	}
+	return cols
+}()
+
+// applyJSONBArraySchema converts jsonbArrayColumns to jsonb and adds a GIN index to each, a
+// no-op unless --jsonb-arrays is set. It's also a no-op under --slim, since applySlimSchema
+// drops these columns outright and there'd be nothing left to convert. Column types are checked
+// first, so rerunning it against an already-converted database is a cheap no-op.
+func applyJSONBArraySchema(db *gorm.DB) error {
+	if !*jsonbArrays || *slim {
+		return nil
+	}
+	for _, c := range jsonbArrayColumns {
+		var dataType string
+		err := db.Raw("SELECT data_type FROM information_schema.columns WHERE table_name = ? AND column_name = ?", c.table, c.column).Scan(&dataType).Error
+		if err != nil {
+			return err
+		}
+		idxName := fmt.Sprintf("idx_%s_%s_gin", c.table, c.column)
+		if dataType != "jsonb" {
+			// indexes (main.go) may have already created idxName as an array-ops GIN index on
+			// this column; drop it first, since ALTER COLUMN TYPE can't rewrite an index built
+			// for the old operator class.
+			if err := db.Exec("DROP INDEX IF EXISTS " + idxName).Error; err != nil {
+				return err
+			}
+			stmt := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE jsonb USING to_jsonb(%s)", c.table, c.column, c.column)
+			if err := db.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		stmt := fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING GIN (%s)", idxName, c.table, c.column)
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -9,107 +9,625 @@ package main
 // San Francisco: "85283457fffffff,852830c7fffffff,85283467fffffff,8528346ffffffff,85283403fffffff,852830d7fffffff,85283477fffffff,8528340bfffffff,8528341bfffffff,85283083fffffff,8528342bfffffff,8528308bfffffff,85283093fffffff,8528343bfffffff,8528309bfffffff,85283443fffffff,85283453fffffff,852836a7fffffff,852830c3fffffff,85283463fffffff,852830cbfffffff,8528346bfffffff,852836b7fffffff,852830d3fffffff,85283473fffffff,852830dbfffffff,85283407fffffff,8528347bfffffff,8528340ffffffff,85283417fffffff,8528308ffffffff,85283447fffffff,8528344ffffffff"
 // Here is a more complete example:
 //   go run main.go "852a1393fffffff,852a104bfffffff,852a1057fffffff" "85283457fffffff,852830c7fffffff,85283467fffffff"
+//
+// Pass --debug-http (and optionally --debug-http-body) to log every outbound HTTP call made to the
+// Pollen and Nominatim APIs, to help diagnose why certain hexes consistently fail.
+//
+// --http-timeout controls the per-request timeout (default 60s), and --max-duration bounds
+// the whole run: once it elapses, in-progress loops checkpoint and return cleanly instead of
+// starting new work, so cron windows are respected.
+//
+// --profile selects a named profile from the --config JSON file (different PG_URL, region, or
+// rate limit per environment), so you can test against a local DB before pointing at production.
+//
+// --max-requests bounds the total number of API calls a run will make, the same way
+// --max-duration bounds wall-clock time, so a huge initial backfill can be spread across several
+// nights. --explain prints how many calls a run would make, making only the hex-list and
+// flower-list calls it needs to count them.
+//
+// --since bounds the whole sync window (rewards and flower history) to dates on or after the
+// given cutoff, for a fresh database that doesn't want years of history pulled in on day one.
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	_ "github.com/joho/godotenv/autoload"
 	"github.com/lib/pq"
 	"github.com/uber/h3-go/v4"
 	"go.uber.org/ratelimit"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 )
 
+var (
+	debugHTTP         = flag.Bool("debug-http", false, "log method, URL, status, and latency for every outbound HTTP call (API key redacted)")
+	debugHTTPBody     = flag.Bool("debug-http-body", false, "also log response bodies when --debug-http is set")
+	httpTimeout       = flag.Duration("http-timeout", time.Second*60, "timeout for a single outbound HTTP call (Pollen API or Nominatim)")
+	maxDuration       = flag.Duration("max-duration", 0, "if set, stop starting new work and exit cleanly once the run has been going this long, so cron windows are respected")
+	healthcheckURL    = flag.String("healthcheck-url", "", "Healthchecks.io / Dead Man's Snitch style check URL to ping at start and on success (appends /start and /fail as needed)")
+	force             = flag.Bool("force", false, "skip the guardrail that aborts a sync when the API returns suspiciously few rows compared to what's already stored")
+	stagingSwap       = flag.Bool("staging-swap", false, "load the full flower refresh into a staging table and atomically swap it in, so readers never see a partially updated table")
+	label             = flag.String("label", "", "tag every row touched by this run with a label, so multiple logical deployments can share one database and be filtered apart")
+	osmAcceptLanguage = flag.String("accept-language", "en", "accept-language to pass to Nominatim for reverse geocoding, so addresses come back in a consistent language instead of the local script")
+	geocodeResolution = flag.Int("flower-geocode-resolution", -1, "H3 resolution to geocode flowers at instead of their stored H3Hex resolution (higher = finer; -1 keeps the stored hex as-is)")
+)
+
+// shrinkageGuardThreshold is the fraction of the previously stored row count below which a
+// sync is considered suspicious (API glitch, silently-failing auth) and aborted.
+const shrinkageGuardThreshold = 0.05
+
+// guardAgainstShrinkage aborts a sync when the API returned 0 rows, or fewer than
+// shrinkageGuardThreshold of what's already stored for table, unless --force is set.
+func guardAgainstShrinkage(db *gorm.DB, table string, newCount int) error {
+	if *force {
+		return nil
+	}
+	var previousCount int64
+	if err := db.Table(table).Count(&previousCount).Error; err != nil {
+		return err
+	}
+	if previousCount == 0 {
+		return nil
+	}
+	if newCount == 0 || float64(newCount) < float64(previousCount)*shrinkageGuardThreshold {
+		return fmt.Errorf("refusing to sync %s: API returned %d rows, down from %d previously stored (use --force to override)", table, newCount, previousCount)
+	}
+	return nil
+}
+
+// runDeadline is the time after which a long-running loop (hex sync, reward sync) should
+// checkpoint and return rather than starting more work. Zero means no deadline.
+var runDeadline time.Time
+
+// deadlineExceeded reports whether --max-duration has elapsed for this run.
+func deadlineExceeded() bool {
+	return !runDeadline.IsZero() && time.Now().After(runDeadline)
+}
+
+// Exit codes, chosen so cron wrappers and monitors can distinguish failure modes without
+// parsing log output.
+const (
+	ExitSuccess        = 0
+	ExitPartialSuccess = 1
+	ExitAuthFailure    = 2
+	ExitDBFailure      = 3
+	ExitRunFailure     = 4
+)
+
 func main() {
-	db, err := gorm.Open(postgres.Open(os.Getenv("PG_URL")), &gorm.Config{
-		Logger: quietLogger(),
-	})
-	handleErr(err)
+	args := os.Args[1:]
+	if len(args) > 0 {
+		if handler, ok := subcommands[args[0]]; ok {
+			os.Exit(handler(args[1:]))
+		}
+	}
+	runSync(args)
+}
+
+// runSync is the original default behavior of the tool (and remains available explicitly as
+// `sync`): migrate the schema, then sync flowers, rewards, and any hex groups passed as args.
+func runSync(args []string) {
+	flag.CommandLine.Parse(args)
+	if *maxDuration > 0 {
+		runDeadline = time.Now().Add(*maxDuration)
+	}
+	pingHealthcheck("start")
+	serveMetrics()
+	summary := runSummary{startedAt: time.Now()}
+
+	var err error
+	activeChangeStream, err = openChangeStream()
+	if err != nil {
+		finish(ExitRunFailure, fmt.Sprintf("result=run_failure error=%q", err))
+	}
+
+	geocodeCache, err = openGeocodeCache()
+	if err != nil {
+		finish(ExitRunFailure, fmt.Sprintf("result=run_failure error=%q", err))
+	}
+
+	activeParquetSink, err = openParquetSink()
+	if err != nil {
+		finish(ExitRunFailure, fmt.Sprintf("result=run_failure error=%q", err))
+	}
+	// Both sinks are closed by finish (not via defer) since every exit from runSync, including
+	// every error path below, goes through it, and os.Exit inside finish would otherwise skip
+	// any deferred Close entirely.
+
+	pgURL := os.Getenv("PG_URL")
+	region, err := loadProfile(&pgURL)
+	if err != nil {
+		finish(ExitRunFailure, fmt.Sprintf("result=run_failure error=%q", err))
+	}
+	if err := loadSecrets(&pgURL); err != nil {
+		finish(ExitRunFailure, fmt.Sprintf("result=run_failure error=%q", err))
+	}
+	if err := applyRDSIAMAuth(&pgURL); err != nil {
+		finish(ExitRunFailure, fmt.Sprintf("result=run_failure error=%q", err))
+	}
+	hexGroups := flag.Args()
+	if len(hexGroups) == 0 && region != "" {
+		hexGroups = []string{region}
+	}
+	if *hexesFile != "" {
+		fileGroups, err := readHexGroupsFile(*hexesFile)
+		if err != nil {
+			finish(ExitRunFailure, fmt.Sprintf("result=run_failure error=%q", err))
+		}
+		hexGroups = append(hexGroups, fileGroups...)
+	}
+	if *expandRing > 0 {
+		for i, hexGroup := range hexGroups {
+			expanded, err := expandHexGroup(hexGroup, *expandRing)
+			if err != nil {
+				finish(ExitRunFailure, fmt.Sprintf("result=run_failure error=%q", err))
+			}
+			hexGroups[i] = expanded
+		}
+	}
+
+	if *explain {
+		if err := runExplain(hexGroups); err != nil {
+			finish(ExitRunFailure, fmt.Sprintf("result=run_failure error=%q", err))
+		}
+		finish(ExitSuccess, "result=success mode=explain")
+	}
+
+	db, err := openDB(pgURL)
+	if err != nil {
+		finish(ExitDBFailure, fmt.Sprintf("result=db_failure error=%q", err))
+	}
+	activeSchemaDrift = &schemaDriftRecorder{db: db}
 	for _, model := range models {
-		handleErr(db.AutoMigrate(&model))
+		if err := db.AutoMigrate(&model); err != nil {
+			finish(ExitDBFailure, fmt.Sprintf("result=db_failure error=%q", err))
+		}
 	}
 	for _, idx := range indexes {
-		handleErr(db.Exec(idx).Error)
+		if err := db.Exec(idx).Error; err != nil {
+			finish(ExitDBFailure, fmt.Sprintf("result=db_failure error=%q", err))
+		}
+	}
+	if err := applyCustomIndexes(db); err != nil {
+		finish(ExitDBFailure, fmt.Sprintf("result=db_failure error=%q", err))
+	}
+	for _, view := range rseViews {
+		if err := db.Exec(view).Error; err != nil {
+			finish(ExitDBFailure, fmt.Sprintf("result=db_failure error=%q", err))
+		}
+	}
+	if err := applySlimSchema(db); err != nil {
+		finish(ExitDBFailure, fmt.Sprintf("result=db_failure error=%q", err))
+	}
+	if err := applyRewardCompositeKey(db); err != nil {
+		finish(ExitDBFailure, fmt.Sprintf("result=db_failure error=%q", err))
+	}
+	if err := applyJSONBArraySchema(db); err != nil {
+		finish(ExitDBFailure, fmt.Sprintf("result=db_failure error=%q", err))
+	}
+	if err := applyStrictSchema(db); err != nil {
+		finish(ExitDBFailure, fmt.Sprintf("result=db_failure error=%q", err))
+	}
+	if err := initGeocodeCache(db); err != nil {
+		finish(ExitDBFailure, fmt.Sprintf("result=db_failure error=%q", err))
+	}
+
+	run := SyncRun{Label: *label, StartedAt: summary.startedAt, Result: "running"}
+	db.Create(&run)
+
+	deltaBefore, deltaErr := captureDeltaSnapshot(db)
+	if deltaErr != nil {
+		logProgress("delta snapshot failed: %v\n", deltaErr)
+	}
+
+	if *watch {
+		runWatch(db, hexGroups)
+		db.Model(&run).Updates(SyncRun{FinishedAt: time.Now(), Result: "watch stopped"})
+		finish(ExitSuccess, "result=success mode=watch")
+	}
+
+	flowersErr := syncFlowers(db)
+	summary.record("flowers", flowersErr)
+	if flowersErr == nil {
+		if err := recordFreshness(db, freshnessFlowersKey, "flowers"); err != nil {
+			logProgress("recording flowers freshness failed: %v\n", err)
+		}
+	}
+	rewardsErr := syncRewards(db)
+	summary.record("rewards", rewardsErr)
+	if rewardsErr == nil {
+		if err := recordFreshness(db, freshnessRewardsKey, "rewards"); err != nil {
+			logProgress("recording rewards freshness failed: %v\n", err)
+		}
+	}
+	hexesOK := len(hexGroups) > 0
+	for _, hexGroup := range hexGroups {
+		if deadlineExceeded() {
+			logProgress("--max-duration reached, checkpointing and exiting before remaining hex groups\n")
+			hexesOK = false
+			break
+		}
+		if requestBudgetExceeded() {
+			logProgress("--max-requests reached, checkpointing and exiting before remaining hex groups\n")
+			hexesOK = false
+			break
+		}
+		if hexGroup == "" {
+			summary.record("hexes", fmt.Errorf("invalid argument passed, should be a comma-separated list of H3 hexes"))
+			hexesOK = false
+			continue
+		}
+		if err := validateHexGroup(hexGroup); err != nil {
+			summary.record("hexes", err)
+			hexesOK = false
+			continue
+		}
+		regionStats, hexErr := syncHexes(db, hexGroup)
+		summary.record("hexes", hexErr)
+		if hexErr != nil {
+			hexesOK = false
+			continue
+		}
+		logProgress("Region %q: hexes_fetched=%d covered=%d new_flowers=%d\n", regionStats.Region, regionStats.HexesFetched, regionStats.Covered, regionStats.NewFlowers)
+		if err := recordRegionStats(db, run.ID, regionStats); err != nil {
+			logProgress("recording region stats for %q failed: %v\n", hexGroup, err)
+		}
+	}
+	if hexesOK {
+		if err := recordFreshness(db, freshnessHexesKey, "hexes"); err != nil {
+			logProgress("recording hexes freshness failed: %v\n", err)
+		}
+	}
+
+	if err := refreshCoverageRollups(db); err != nil {
+		logProgress("refreshing coverage rollups failed: %v\n", err)
+	}
+	if err := refreshMaterializedViews(db); err != nil {
+		logProgress("refreshing materialized views failed: %v\n", err)
 	}
 
-	handleErr(initGeocodeCache(db))
+	summary.record("bounty-alerts", checkExpiringBountyAlerts(db))
 
-	handleErr(syncFlowers(db))
-	handleErr(syncRewards(db))
-	for _, hexGroup := range os.Args[1:] {
-		if hexGroup == "" || !isValidHex(hexGroup) {
-			panic(fmt.Errorf("invalid argument passed, should be a comma-separated list of H3 hexes"))
+	if deltaErr == nil {
+		if deltaAfter, err := captureDeltaSnapshot(db); err != nil {
+			logProgress("delta snapshot failed: %v\n", err)
+		} else if err := writeDelta(db, deltaBefore, deltaAfter); err != nil {
+			logProgress("writing delta failed: %v\n", err)
 		}
-		handleErr(syncHexes(db, hexGroup))
 	}
+
+	db.Model(&run).Updates(SyncRun{FinishedAt: time.Now(), Result: summary.status()})
+	if err := notifySyncComplete(db, run, &summary); err != nil {
+		logProgress("pg_notify failed: %v\n", err)
+	}
+	if err := recordRunOutcome(db, summary.exitCode() != ExitSuccess); err != nil {
+		logProgress("recording run outcome for escalation failed: %v\n", err)
+	}
+	finish(summary.exitCode(), summary.String())
+}
+
+// runSummary accumulates per-phase outcomes so a single machine-parseable line can be
+// printed at the end of the run for cron monitors.
+type runSummary struct {
+	startedAt time.Time
+	ranPhases int
+	okPhases  int
+	errs      []string
+	authFail  bool
+	dbFail    bool
+}
+
+func (s *runSummary) record(phase string, err error) {
+	s.ranPhases++
+	if err == nil {
+		s.okPhases++
+		return
+	}
+	s.errs = append(s.errs, fmt.Sprintf("%s: %v", phase, err))
+	if isAuthError(err) {
+		s.authFail = true
+	}
+	if isDBError(err) {
+		s.dbFail = true
+	}
+}
+
+func (s *runSummary) exitCode() int {
+	switch {
+	case len(s.errs) == 0:
+		return ExitSuccess
+	case s.authFail:
+		return ExitAuthFailure
+	case s.dbFail:
+		return ExitDBFailure
+	case s.okPhases > 0:
+		return ExitPartialSuccess
+	default:
+		return ExitRunFailure
+	}
+}
+
+func (s *runSummary) status() string {
+	switch s.exitCode() {
+	case ExitSuccess:
+		return "success"
+	case ExitPartialSuccess:
+		return "partial_success"
+	case ExitAuthFailure:
+		return "auth_failure"
+	case ExitDBFailure:
+		return "db_failure"
+	default:
+		return "failure"
+	}
+}
+
+func (s *runSummary) String() string {
+	return fmt.Sprintf("result=%s phases_ok=%d phases_ran=%d duration=%s errors=%d %s %s",
+		s.status(), s.okPhases, s.ranPhases, time.Since(s.startedAt).Round(time.Millisecond), len(s.errs), metricsSummary(), apiLatencySummary())
+}
+
+func isAuthError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "401") || strings.Contains(msg, "403") ||
+		strings.Contains(msg, "unauthorized") || strings.Contains(msg, "authentication")
+}
+
+func isDBError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "pq:") || strings.Contains(msg, "sqlstate") ||
+		strings.Contains(msg, "connection refused") || errors.Is(err, gorm.ErrInvalidDB)
+}
+
+// dbWriteRetries and dbWriteRetryBaseDelay bound the backoff applied to transient write
+// failures. Managed Postgres instances routinely drop idle connections and occasionally
+// deadlock or fail serialization under concurrent writers, none of which are worth aborting
+// the whole run over.
+const (
+	dbWriteRetries        = 3
+	dbWriteRetryBaseDelay = time.Second * 2
+)
+
+// isTransientDBError reports whether err looks like a deadlock, serialization failure, or
+// dropped connection, as opposed to a bad query or constraint violation that retrying won't fix.
+func isTransientDBError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, substr := range []string{
+		"deadlock detected",
+		"could not serialize access",
+		"connection reset",
+		"broken pipe",
+		"eof",
+		"connection refused",
+		"conn closed",
+		"too many connections",
+	} {
+		if strings.Contains(msg, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// withDBRetry retries fn with bounded exponential backoff when it fails with a transient DB
+// error, so a sync doesn't abort over a dropped idle connection mid-run.
+func withDBRetry(fn func() error) error {
+	var err error
+	for attempt := 0; attempt <= dbWriteRetries; attempt++ {
+		if err = fn(); err == nil || !isTransientDBError(err) {
+			return err
+		}
+		if attempt < dbWriteRetries {
+			time.Sleep(dbWriteRetryBaseDelay * time.Duration(1<<attempt))
+		}
+	}
+	return err
+}
+
+// finish prints the final run summary, pings the configured healthcheck, closes the
+// parquet/change-stream sinks, and exits with code, so wrapper scripts and zero-effort cron
+// monitors can both react. It closes those sinks itself, rather than relying on runSync's
+// deferred Close calls, because os.Exit skips deferred calls entirely: on any error path after
+// the sinks are opened, the deferred Close would otherwise never run, leaving an unflushed
+// (corrupt) Parquet file and dangling Kafka/NATS connections.
+func finish(code int, summary string) {
+	if code == ExitSuccess {
+		pingHealthcheck("")
+	} else {
+		pingHealthcheck("fail")
+	}
+	if err := activeParquetSink.Close(); err != nil {
+		logProgress("%v\n", err)
+	}
+	activeChangeStream.Close()
+	fmt.Println(summary)
+	os.Exit(code)
+}
+
+// pingHealthcheck GETs --healthcheck-url (optionally with suffix appended, e.g. "start" or
+// "fail") for Healthchecks.io / Dead Man's Snitch style cron monitoring. It is best-effort:
+// a failed ping is logged but never fails the run.
+func pingHealthcheck(suffix string) {
+	if *healthcheckURL == "" {
+		return
+	}
+	url := *healthcheckURL
+	if suffix != "" {
+		url = strings.TrimRight(url, "/") + "/" + suffix
+	}
+	cli := http.Client{Timeout: time.Second * 10}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		logProgress("healthcheck ping to %s failed: %v\n", url, err)
+		return
+	}
+	res, err := doHTTP(&cli, req)
+	if err != nil {
+		logProgress("healthcheck ping to %s failed: %v\n", url, err)
+		return
+	}
+	res.Body.Close()
 }
 
 func syncRewards(db *gorm.DB) error {
-	var flowerNames []string
-	err :=
-		db.
-			Table(tableNameFlower).Select("id").
-			Find(&flowerNames).Error
+	var candidates []rewardCandidate
+	err := db.Table(tableNameFlower).Select("id, update_time").Find(&candidates).Error
 	if err != nil {
 		return err
 	}
-	fmt.Printf("Found %v reward candidates\n", len(flowerNames))
-	for i, flowerName := range flowerNames {
+	logProgress("Found %v reward candidates\n", len(candidates))
+
+	if *rewardWorkers > 1 {
+		if err := syncRewardsParallel(db, candidates); err != nil {
+			return err
+		}
+		return clearRewardCheckpoints(db)
+	}
+
+	for i, c := range candidates {
+		if deadlineExceeded() {
+			logProgress("--max-duration reached, checkpointing reward sync at %d/%d\n", i, len(candidates))
+			return nil
+		}
+		if requestBudgetExceeded() {
+			logProgress("--max-requests reached, checkpointing reward sync at %d/%d\n", i, len(candidates))
+			return nil
+		}
 		if i%100 == 0 {
-			fmt.Printf("Reward progress: %d/%d\n", i, len(flowerNames))
+			logProgress("Reward progress: %d/%d\n", i, len(candidates))
 		}
 
-		rewardItems, err := getRewards(flowerName)
-		if err != nil {
+		if err := syncOneReward(db, c); err != nil {
 			return err
 		}
-		rewards := []Reward{}
-		for _, r := range rewardItems {
-			coverage := []string{}
-			switch v := r.Coverage.(type) {
-			case []string:
-				coverage = v
+	}
+	return clearRewardCheckpoints(db)
+}
+
+// fetchAndStoreRewards fetches flowerName's reward history and upserts it, recording a
+// decode/dead-letter failure and returning errRewardDeadLettered instead of the underlying error
+// so a single bad device doesn't have to abort the caller's loop over every other flower, while
+// still letting a caller that fingerprints success (syncOneReward) tell the difference from a
+// true success. applyDateRange scopes the fetched items to [--rewards-since/--since,
+// --rewards-until] the way syncRewards wants; backfill.go passes false since a backfill's entire
+// purpose is ingesting full history regardless of that window.
+func fetchAndStoreRewards(db *gorm.DB, flowerName string, applyDateRange bool) error {
+	rewardItems, err := getRewards(flowerName)
+	if err != nil {
+		var derr *decodeError
+		if errors.As(err, &derr) {
+			if ferr := writeDecodeFailure(db, "rewards", flowerName, derr.Body, derr.Err); ferr != nil {
+				return ferr
 			}
-			rewards = append(rewards, Reward{
-				ID:                r.RewardID,
-				PCN:               r.PCN,
-				PIC:               r.PIC,
-				RSERatio:          r.RSERatio,
-				Client:            r.Client,
-				Coverage:          pq.StringArray(coverage),
-				DailyPIC:          r.DailyPIC,
-				Date:              r.Date,
-				Device:            r.Device,
-				DeviceType:        r.DeviceType,
-				Reward:            r.Reward,
-				Transaction:       r.Transaction,
-				TransactionStatus: r.TransactionStatus,
-				Wallet:            r.Wallet,
-			})
 		}
-		if err := db.Clauses(upsertClause).CreateInBatches(&rewards, 200).Error; err != nil {
+		if ferr := recordRewardFailure(db, flowerName, err); ferr != nil {
+			return ferr
+		}
+		return errRewardDeadLettered
+	}
+	if applyDateRange {
+		rewardItems = filterRewardsByDateRange(rewardItems)
+	}
+	rewards := []Reward{}
+	for _, r := range rewardItems {
+		coverage := []string{}
+		switch v := r.Coverage.(type) {
+		case []string:
+			coverage = v
+		}
+		rewards = append(rewards, Reward{
+			ID:                r.RewardID,
+			PCN:               float64(r.PCN),
+			PIC:               float64(r.PIC),
+			RSERatio:          float64(r.RSERatio),
+			Client:            r.Client,
+			Coverage:          flexArray(coverage),
+			DailyPIC:          float64(r.DailyPIC),
+			Date:              parseRewardDate(r.Date),
+			Device:            r.Device,
+			DeviceType:        r.DeviceType,
+			Reward:            r.Reward,
+			Transaction:       r.Transaction,
+			TransactionStatus: r.TransactionStatus,
+			Wallet:            r.Wallet,
+			Label:             *label,
+		})
+	}
+	err = withDBRetry(func() error {
+		return db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Omit(slimOmitFields(tableNameReward)...).Clauses(rewardUpsertClause()).CreateInBatches(&rewards, 200).Error; err != nil {
+				return err
+			}
+			if err := writeRewardCoverage(tx, rewards); err != nil {
+				return err
+			}
+			if err := recordValidators(tx, rewardItems); err != nil {
+				return err
+			}
+			return recordClients(tx, flowerName, rewardItems)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	if err := activeParquetSink.WriteRewards(rewards); err != nil {
+		return err
+	}
+	for _, r := range rewards {
+		if err := activeChangeStream.Publish(tableNameReward, "upsert", r); err != nil {
 			return err
 		}
 	}
-	return nil
+	return clearRewardFailure(db, flowerName)
 }
 
 func syncFlowers(db *gorm.DB) error {
 	flowerItems, err := getAllFlowers()
+	if err != nil {
+		var derr *decodeError
+		if errors.As(err, &derr) {
+			if ferr := writeDecodeFailure(db, "flowers", "", derr.Body, derr.Err); ferr != nil {
+				return ferr
+			}
+			logProgress("Flower list failed to decode, recorded to %s and skipping this sync\n", tableNameDecodeFailure)
+			return nil
+		}
+		return err
+	}
+	logProgress("Found %v flowers\n", len(flowerItems))
+	if err := guardAgainstShrinkage(db, tableNameFlower, len(flowerItems)); err != nil {
+		return err
+	}
+
+	sentinel := flowersSentinel(flowerItems)
+	previousSentinel, err := getSyncState(db, flowersSentinelKey)
+	if err != nil {
+		return err
+	}
+	if !*force && sentinel == previousSentinel {
+		logProgress("Flowers unchanged since last sync (no-op run), skipping write\n")
+		return nil
+	}
+
+	previousIdentities, err := loadFlowerIdentities(db)
 	if err != nil {
 		return err
 	}
-	fmt.Printf("Found %v flowers\n", len(flowerItems))
 
 	flowers := make([]Flower, len(flowerItems))
 	for i, f := range flowerItems {
@@ -117,22 +635,25 @@ func syncFlowers(db *gorm.DB) error {
 		if err != nil {
 			return err
 		}
-		geo, err := reverseGeocode(f.H3Hex)
+		geo, err := reverseGeocode(flowerGeocodeHex(f.H3Hex))
 		if err != nil {
 			return err
 		}
+		if err := writeHeatmapCells(db, append(f.CoveredHexes, f.DailyCoveredHexes...)); err != nil {
+			return err
+		}
 		flowers[i] = Flower{
 			ID:                f.ID,
-			BountyRewards:     f.BountyRewards,
+			BountyRewards:     int(f.BountyRewards),
 			DisplayName:       f.DisplayName,
 			UpdateTime:        f.UpdateTime,
-			DailyBeesSeen:     pq.StringArray(f.DailyBeesSeen),
-			FirstSeen:         f.FirstSeen,
-			HBeesSeen:         pq.StringArray(f.HBeesSeen),
+			DailyBeesSeen:     flexArray(f.DailyBeesSeen),
+			FirstSeen:         parseNullableTime(f.FirstSeen),
+			HBeesSeen:         flexArray(f.HBeesSeen),
 			WalletAddress:     f.WalletAddress,
-			CoveredHexes:      pq.StringArray(f.CoveredHexes),
-			LastSeen:          f.LastSeen,
-			DailyAttaches:     f.DailyAttaches,
+			CoveredHexes:      flexArray(f.CoveredHexes),
+			LastSeen:          parseNullableTime(f.LastSeen),
+			DailyAttaches:     int(f.DailyAttaches),
 			H3Hex:             f.H3Hex,
 			Lat:               geo.Lat,
 			Lng:               geo.Lng,
@@ -142,162 +663,384 @@ func syncFlowers(db *gorm.DB) error {
 			State:             geo.State,
 			Town:              geo.Town,
 			County:            geo.County,
-			Active:            f.Active,
-			FlowerRewards:     f.FlowerRewards,
-			DailyCoveredHexes: pq.StringArray(f.DailyCoveredHexes),
+			Active:            int(f.Active),
+			FlowerRewards:     float64(f.FlowerRewards),
+			DailyCoveredHexes: flexArray(f.DailyCoveredHexes),
 			NFTAddress:        f.NFTAddress,
 			Nickname:          f.Nickname,
-			FlowerAttaches:    f.FlowerAttaches,
-			DailyHBeesSeen:    pq.StringArray(f.DailyHBeesSeen),
-			DailyRewards:      f.DailyRewards,
+			FlowerAttaches:    int(f.FlowerAttaches),
+			DailyHBeesSeen:    flexArray(f.DailyHBeesSeen),
+			DailyRewards:      float64(f.DailyRewards),
 			ImageURL:          f.ImageURL,
 			BeesSeen:          string(beesSeen),
+			Label:             *label,
 		}
 	}
-	return db.Clauses(upsertClause).CreateInBatches(&flowers, 200).Error
-}
-
-func syncHexes(db *gorm.DB, hexLocations string) error {
-	hexes, err := getAllHexes(hexLocations)
-	if err != nil {
+	if *stagingSwap {
+		if err := syncFlowersViaStaging(db, flowers); err != nil {
+			return err
+		}
+	} else {
+		err := withDBRetry(func() error {
+			return db.Transaction(func(tx *gorm.DB) error {
+				return tx.Omit(slimOmitFields(tableNameFlower)...).Clauses(upsertClause).CreateInBatches(&flowers, 200).Error
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := writeFlowerHistory(db, flowers); err != nil {
+		return err
+	}
+	if err := writeFlowerChanges(db, detectFlowerChanges(previousIdentities, flowers)); err != nil {
+		return err
+	}
+	if err := writeFlowerTransfers(db, detectFlowerTransfers(previousIdentities, flowers)); err != nil {
+		return err
+	}
+	if err := writeFlowerRelocations(db, detectFlowerRelocations(previousIdentities, flowers)); err != nil {
+		return err
+	}
+	if err := activeParquetSink.WriteFlowers(flowers); err != nil {
 		return err
 	}
-	fmt.Printf("Found %v hexes\n", len(hexes))
+	for _, f := range flowers {
+		if err := activeChangeStream.Publish(tableNameFlower, "upsert", f); err != nil {
+			return err
+		}
+	}
+	return setSyncState(db, flowersSentinelKey, sentinel)
+}
 
-	for i, hex := range hexes {
-		if i%100 == 0 {
-			fmt.Printf("Hex progress: %v / %v\n", i, len(hexes))
+// flowersSentinelKey is the SyncState key under which the flowers payload sentinel is stored.
+const flowersSentinelKey = "flowers_sentinel"
+
+// flowersSentinel computes a cheap fingerprint of the flowers payload (count + max
+// update_time) so an unchanged upstream dataset can be detected without hashing every field.
+func flowersSentinel(items []FlowerListItem) string {
+	maxUpdateTime := ""
+	for _, f := range items {
+		if f.UpdateTime > maxUpdateTime {
+			maxUpdateTime = f.UpdateTime
 		}
+	}
+	return fmt.Sprintf("%d:%s", len(items), maxUpdateTime)
+}
 
-		details, err := getHexDetails(hex.ID)
-		if err != nil {
-			return err
+// syncFlowersViaStaging loads flowers into a staging table and atomically renames it into
+// place, so readers never observe a partially-updated pollen_flowers table during the sync.
+// --strict-schema's FK constraints into pollen_flowers (fkschema.go) are dropped before the
+// rename and re-added after, in the same transaction as the swap: they're resolved by
+// pollen_flowers's OID, not its name, so left alone they'd still point at the old table after
+// the rename and block its final DROP TABLE.
+func syncFlowersViaStaging(db *gorm.DB, flowers []Flower) error {
+	staging := tableNameFlower + "_staging"
+	old := tableNameFlower + "_old"
+	return withDBRetry(func() error {
+		return db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", staging)).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(fmt.Sprintf("CREATE TABLE %s (LIKE %s INCLUDING ALL)", staging, tableNameFlower)).Error; err != nil {
+				return err
+			}
+			if err := tx.Table(staging).Omit(slimOmitFields(tableNameFlower)...).Clauses(upsertClause).CreateInBatches(&flowers, 200).Error; err != nil {
+				return err
+			}
+			if err := dropFlowerForeignKeys(tx); err != nil {
+				return err
+			}
+			if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s RENAME TO %s", tableNameFlower, old)).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s RENAME TO %s", staging, tableNameFlower)).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", old)).Error; err != nil {
+				return err
+			}
+			return addFlowerForeignKeys(tx)
+		})
+	})
+}
+
+// prioritizeHexes sorts hexes so covered hexes and hexes with flowers are synced first, so the
+// most valuable data lands early even if the run is interrupted or deadline-bound.
+func prioritizeHexes(hexes []HexListItem) {
+	sort.SliceStable(hexes, func(i, j int) bool {
+		if (hexes[i].FlowerCount > 0) != (hexes[j].FlowerCount > 0) {
+			return hexes[i].FlowerCount > 0
 		}
-		geo, err := reverseGeocode(hex.ID)
-		if err != nil {
-			return err
+		if (hexes[i].Covered > 0) != (hexes[j].Covered > 0) {
+			return hexes[i].Covered > 0
 		}
-		err = db.
-			Clauses(upsertClause).
-			Create(&Hex{
-				ID:               hex.ID,
-				FlowerCount:      hex.FlowerCount,
-				Covered:          hex.Covered,
-				Lat:              geo.Lat,
-				Lng:              geo.Lng,
-				Address:          geo.Address,
-				Suburb:           geo.Suburb,
-				City:             geo.City,
-				State:            geo.State,
-				Town:             geo.Town,
-				County:           geo.County,
-				Attach:           details.Hex.Attach,
-				Flowers:          pq.StringArray(details.Hex.Flowers),
-				FlowersContained: pq.StringArray(details.Hex.FlowersContained),
-				BountyReward:     details.Hex.BountyReward,
-				LootBoxReward:    details.Hex.LootBoxReward,
-				DailyReward:      details.Hex.DailyReward,
-				Bounty:           details.Hex.Bounty,
-				BountyTime:       details.Hex.BountyTime,
-			}).
-			Error
-		if err != nil {
-			return err
+		return false
+	})
+}
+
+// syncHexes syncs one hex group ("region"), returning its regionSyncStats so callers that sync
+// several hex groups in one run can report and store them separately instead of only a combined
+// total.
+func syncHexes(db *gorm.DB, hexLocations string) (regionSyncStats, error) {
+	stats := regionSyncStats{Region: hexLocations}
+	if err := writeHeatmapCells(db, strings.Split(hexLocations, ",")); err != nil {
+		return stats, err
+	}
+	hexes, err := getAllHexes(hexLocations)
+	if err != nil {
+		var derr *decodeError
+		if errors.As(err, &derr) {
+			if ferr := writeDecodeFailure(db, "hexes", hexLocations, derr.Body, derr.Err); ferr != nil {
+				return stats, ferr
+			}
+			logProgress("Hex list for %q failed to decode, recorded to %s and skipping this sync\n", hexLocations, tableNameDecodeFailure)
+			return stats, nil
 		}
+		return stats, err
 	}
-	return nil
+	logProgress("Found %v hexes\n", len(hexes))
+	hexes, err = withQueuedHexRetries(db, hexes)
+	if err != nil {
+		return stats, err
+	}
+	prioritizeHexes(hexes)
+
+	pipelineStats, err := syncHexesPipelined(db, hexes)
+	if err != nil {
+		return stats, err
+	}
+	pipelineStats.Region = hexLocations
+	return pipelineStats, nil
+}
+
+// withQueuedHexRetries folds any hex IDs left in the retry queue by a previous run's exhausted
+// retries back into hexes, so they're retried automatically instead of needing a manual re-run.
+// Their fingerprint fields are left unset so the unchanged-since-last-sync skip never applies to
+// a hex whose last fetch actually failed.
+func withQueuedHexRetries(db *gorm.DB, hexes []HexListItem) ([]HexListItem, error) {
+	queued, err := pendingRetryKeys(db, "hex")
+	if err != nil {
+		return hexes, err
+	}
+	seen := make(map[string]bool, len(hexes))
+	for _, h := range hexes {
+		seen[h.ID] = true
+	}
+	for _, id := range queued {
+		if !seen[id] {
+			hexes = append(hexes, HexListItem{ID: id})
+			seen[id] = true
+		}
+	}
+	return hexes, nil
+}
+
+// upsertHex fetches full details and the geocode for a single hex list entry and upserts it,
+// the unit of work shared by the region sync loop and the `hex show --refresh` subcommand.
+func upsertHex(db *gorm.DB, hex HexListItem) error {
+	details, err := getHexDetails(hex.ID)
+	if err != nil {
+		return err
+	}
+	geo, err := reverseGeocode(hex.ID)
+	if err != nil {
+		return err
+	}
+	return writeHex(db, hex, details, geo)
+}
+
+// writeHex upserts a single hex's row, given its already-fetched API details and geocode. It's
+// the shared write stage for both the single-item upsertHex path and the pipelined hex sync.
+func writeHex(db *gorm.DB, hex HexListItem, details HexItem, geo ReverseGeocode) error {
+	if err := writeHeatmapCells(db, []string{hex.ID}); err != nil {
+		return err
+	}
+	if err := writeHexHistory(db, hex, details); err != nil {
+		return err
+	}
+	if err := recordPollenDrop(db, hex.ID, details.Hex.LastPollenDrop); err != nil {
+		return err
+	}
+	if err := recordHexBounty(db, hex.ID, details.Hex.H3HexTop, details.Hex.Bounty, float64(details.Hex.BountyReward), details.Hex.BountyTime); err != nil {
+		return err
+	}
+	if err := recordLootBoxEvent(db, hex.ID, int(details.Hex.LootBoxReward)); err != nil {
+		return err
+	}
+	row := Hex{
+		ID:               hex.ID,
+		FlowerCount:      hex.FlowerCount,
+		Covered:          hex.Covered,
+		Lat:              geo.Lat,
+		Lng:              geo.Lng,
+		Address:          geo.Address,
+		Suburb:           geo.Suburb,
+		City:             geo.City,
+		State:            geo.State,
+		Town:             geo.Town,
+		County:           geo.County,
+		Attach:           int(details.Hex.Attach),
+		Flowers:          pq.StringArray(details.Hex.Flowers),
+		FlowersContained: pq.StringArray(details.Hex.FlowersContained),
+		BountyReward:     float64(details.Hex.BountyReward),
+		LootBoxReward:    int(details.Hex.LootBoxReward),
+		DailyReward:      int(details.Hex.DailyReward),
+		Bounty:           details.Hex.Bounty,
+		BountyTime:       details.Hex.BountyTime,
+		SignalStrength:   int(details.Hex.SignalStrength),
+		Ping:             float64(details.Hex.Ping),
+		LastCovered:      details.Hex.LastCovered,
+		LastPollenDrop:   details.Hex.LastPollenDrop,
+		Device:           pq.StringArray(details.Hex.Device),
+		H3HexTop:         details.Hex.H3HexTop,
+		Label:            *label,
+	}
+	if err := db.
+		Omit(slimOmitFields(tableNameHex)...).
+		Clauses(upsertClause).
+		Create(&row).
+		Error; err != nil {
+		return err
+	}
+	if err := activeParquetSink.WriteHex(row); err != nil {
+		return err
+	}
+	return activeChangeStream.Publish(tableNameHex, "upsert", row)
 }
 
 // Postgres DB Schema
-//
 type (
 	Hex struct {
-		ID               string `gorm:"primaryKey"`
-		FlowerCount      int
-		Covered          int
-		Lat              float64
-		Lng              float64
-		Address          string
-		Suburb           string
-		City             string
-		State            string
-		Town             string
-		County           string
-		Attach           int
-		Flowers          pq.StringArray `gorm:"type:text[]"`
-		FlowersContained pq.StringArray `gorm:"type:text[]"`
-		BountyReward     float64
-		LootBoxReward    int
-		DailyReward      int
-		Bounty           string
-		BountyTime       string
-		UpdatedAt        time.Time `gorm:"not null;default:current_timestamp"`
+		ID               string         `gorm:"primaryKey" doc:"H3 hex ID of a cell inside one of the synced res-5 hex groups (hexes list: h3_hex)."`
+		FlowerCount      int            `doc:"Number of flowers reported covering this hex (hexes list: flower_count)."`
+		Covered          int            `doc:"Nonzero if the hex has any coverage at all (hexes list: covered)."`
+		Lat              float64        `doc:"Latitude geocoded from the hex's centroid via reverse geocoding, not a Pollen API field."`
+		Lng              float64        `doc:"Longitude geocoded from the hex's centroid via reverse geocoding, not a Pollen API field."`
+		Address          string         `doc:"Reverse-geocoded street address."`
+		Suburb           string         `doc:"Reverse-geocoded suburb/neighborhood."`
+		City             string         `doc:"Reverse-geocoded city."`
+		State            string         `doc:"Reverse-geocoded state/province."`
+		Town             string         `doc:"Reverse-geocoded town."`
+		County           string         `doc:"Reverse-geocoded county."`
+		Attach           int            `doc:"hex.attach from the hex detail endpoint."`
+		Flowers          pq.StringArray `gorm:"type:text[]" doc:"Flower IDs attached to this hex (hex detail: hex.flowers)."`
+		FlowersContained pq.StringArray `gorm:"type:text[]" doc:"Flower IDs this hex's coverage contains (hex detail: hex.flowers_contained)."`
+		BountyReward     float64        `doc:"hex.bountyReward from the hex detail endpoint."`
+		LootBoxReward    int            `doc:"hex.lootBoxReward from the hex detail endpoint."`
+		DailyReward      int            `doc:"hex.dailyReward from the hex detail endpoint."`
+		Bounty           string         `doc:"hex.bounty from the hex detail endpoint."`
+		BountyTime       string         `doc:"hex.bounty_time from the hex detail endpoint."`
+		SignalStrength   int            `doc:"hex.signalStrength from the hex detail endpoint."`
+		Ping             float64        `doc:"hex.ping from the hex detail endpoint."`
+		LastCovered      string         `doc:"hex.last_covered from the hex detail endpoint."`
+		LastPollenDrop   string         `doc:"hex.last_pollen_drop from the hex detail endpoint."`
+		Device           pq.StringArray `gorm:"type:text[]" doc:"Device IDs reported on this hex (hex detail: hex.device)."`
+		H3HexTop         string         `gorm:"index" doc:"Res-5 region hex this hex rolls up to (hex detail: hex.h3_hex_top)."`
+		Label            string         `gorm:"index" doc:"--label value of the sync run that wrote this row, for multi-tenant databases."`
+		// CreatedAt is set once, the first time this tool sees the hex, and is excluded
+		// from upsertClause's UpdateAll by gorm's AutoCreateTime convention, so it survives
+		// every later sync untouched.
+		CreatedAt time.Time `gorm:"not null;default:current_timestamp"`
+		UpdatedAt time.Time `gorm:"not null;default:current_timestamp"`
 	}
 
 	Flower struct {
-		ID                string `gorm:"primaryKey"`
-		BountyRewards     int
-		DisplayName       string
-		UpdateTime        string
-		DailyBeesSeen     pq.StringArray `gorm:"type:text[]"`
-		FirstSeen         *string
-		HBeesSeen         pq.StringArray `gorm:"type:text[]"`
-		WalletAddress     string
-		CoveredHexes      pq.StringArray `gorm:"type:text[]"`
-		LastSeen          *string
-		DailyAttaches     int
-		H3Hex             string
-		Lat               float64
-		Lng               float64
-		Address           string
-		Suburb            string
-		City              string
-		State             string
-		Town              string
-		County            string
-		Active            int
-		FlowerRewards     float64
-		DailyCoveredHexes pq.StringArray `gorm:"type:text[]"`
-		NFTAddress        string
-		Nickname          string
-		FlowerAttaches    int
-		DailyHBeesSeen    pq.StringArray `gorm:"type:text[]"`
-		DailyRewards      float64
-		ImageURL          string
-		BeesSeen          string
-		UpdatedAt         time.Time `gorm:"not null;default:current_timestamp"`
+		ID                string     `gorm:"primaryKey" doc:"Flower device ID (flowers list: flowerID)."`
+		BountyRewards     int        `doc:"flowers list: bounty_rewards."`
+		DisplayName       string     `doc:"flowers list: displayname."`
+		UpdateTime        string     `doc:"flowers list: update_time; compared against RewardSyncFingerprint to skip unchanged reward fetches."`
+		DailyBeesSeen     flexArray  `gorm:"type:text[]" doc:"flowers list: daily_bees_seen."`
+		FirstSeen         *time.Time `doc:"flowers list: first_seen."`
+		HBeesSeen         flexArray  `gorm:"type:text[]" doc:"flowers list: hbees_seen."`
+		WalletAddress     string     `doc:"flowers list: wallet_address."`
+		CoveredHexes      flexArray  `gorm:"type:text[]" doc:"Hexes this flower's coverage includes (flowers list: covered_hexes)."`
+		LastSeen          *time.Time `doc:"flowers list: last_seen."`
+		DailyAttaches     int        `doc:"flowers list: daily_attaches."`
+		H3Hex             string     `doc:"flowers list: h3_hex."`
+		Lat               float64    `doc:"Latitude geocoded from H3Hex via reverse geocoding, not a Pollen API field."`
+		Lng               float64    `doc:"Longitude geocoded from H3Hex via reverse geocoding, not a Pollen API field."`
+		Address           string     `doc:"Reverse-geocoded street address."`
+		Suburb            string     `doc:"Reverse-geocoded suburb/neighborhood."`
+		City              string     `doc:"Reverse-geocoded city."`
+		State             string     `doc:"Reverse-geocoded state/province."`
+		Town              string     `doc:"Reverse-geocoded town."`
+		County            string     `doc:"Reverse-geocoded county."`
+		Active            int        `doc:"flowers list: attach."`
+		FlowerRewards     float64    `doc:"flowers list: flower_rewards."`
+		DailyCoveredHexes flexArray  `gorm:"type:text[]" doc:"flowers list: daily_covered_hexes."`
+		NFTAddress        string     `doc:"flowers list: nft_address."`
+		Nickname          string     `doc:"flowers list: nickname."`
+		FlowerAttaches    int        `doc:"flowers list: flower_attaches."`
+		DailyHBeesSeen    flexArray  `gorm:"type:text[]" doc:"flowers list: daily_hbees_seen."`
+		DailyRewards      float64    `doc:"flowers list: daily_rewards."`
+		ImageURL          string     `doc:"flowers list: image_url."`
+		BeesSeen          string     `doc:"flowers list: bees_seen, flattened from a map to a string."`
+		Label             string     `gorm:"index" doc:"--label value of the sync run that wrote this row, for multi-tenant databases."`
+		UpdatedAt         time.Time  `gorm:"not null;default:current_timestamp" doc:"Bookkeeping: when this row was last upserted."`
 	}
 
 	Reward struct {
-		ID                string `gorm:"primaryKey"`
-		PCN               float64
-		PIC               float64
-		RSERatio          float64
-		Client            string
-		Coverage          pq.StringArray `gorm:"type:text[]"`
-		DailyPIC          float64
-		Date              string
-		Device            string
-		DeviceType        string
-		Reward            string
-		Transaction       string
-		TransactionStatus string
-		Wallet            string
-		UpdatedAt         time.Time `gorm:"not null;default:current_timestamp"`
+		ID                string    `gorm:"primaryKey" doc:"Reward record ID (reward history: rewardID)."`
+		PCN               float64   `doc:"Pollen Coin earned (reward history: PCN)."`
+		PIC               float64   `doc:"reward history: PIC."`
+		RSERatio          float64   `doc:"Reward Share Efficiency ratio (reward history: RSEratio)."`
+		Client            string    `doc:"reward history: client."`
+		Coverage          flexArray `gorm:"type:text[]" doc:"Hexes this reward's coverage spans (reward history: coverage); also normalized into RewardCoverage (rewardcoverage.go)."`
+		DailyPIC          float64   `doc:"reward history: dailyPIC."`
+		Date              time.Time `gorm:"type:date;index:idx_reward_device_date,priority:2;index:idx_reward_wallet_date,priority:2" doc:"reward history: date."`
+		Device            string    `gorm:"index:idx_reward_device_date,priority:1" doc:"Device (flower) ID this reward was earned by (reward history: device)."`
+		DeviceType        string    `doc:"reward history: device_type."`
+		Reward            string    `doc:"reward history: reward."`
+		Transaction       string    `doc:"reward history: transaction."`
+		TransactionStatus string    `doc:"reward history: tx_status."`
+		Wallet            string    `gorm:"index:idx_reward_wallet_date,priority:1" doc:"Payout wallet address (reward history: wallet)."`
+		Label             string    `gorm:"index" doc:"--label value of the sync run that wrote this row, for multi-tenant databases."`
+		UpdatedAt         time.Time `gorm:"not null;default:current_timestamp" doc:"Bookkeeping: when this row was last upserted."`
+	}
+
+	// SyncState holds small bookkeeping values (sentinels, checkpoints) keyed by name,
+	// shared across run types instead of each growing its own one-off table.
+	SyncState struct {
+		Key       string `gorm:"primaryKey"`
+		Value     string
+		UpdatedAt time.Time `gorm:"not null;default:current_timestamp"`
+	}
+
+	// SyncRun records one invocation of the sync command, so runs made under different
+	// --label values (e.g. per-team regions sharing one database) can be audited apart.
+	SyncRun struct {
+		ID         uint   `gorm:"primaryKey"`
+		Label      string `gorm:"index"`
+		StartedAt  time.Time
+		FinishedAt time.Time
+		Result     string
 	}
 )
 
 var (
-	tableNameHex    = "pollen_hexes"
-	tableNameFlower = "pollen_flowers"
-	tableNameReward = "pollen_rewards"
-	indexes         = []string{
+	tableNameHex       = "pollen_hexes"
+	tableNameFlower    = "pollen_flowers"
+	tableNameReward    = "pollen_rewards"
+	tableNameSyncState = "pollen_sync_state"
+	tableNameSyncRun   = "pollen_sync_runs"
+	indexes            = []string{
 		"CREATE INDEX IF NOT EXISTS idx_pollen_rewards_device ON pollen_rewards (device, date DESC)",
+		"CREATE INDEX IF NOT EXISTS idx_pollen_reward_coverage_hex ON pollen_reward_coverage (hex_id)",
+		// GIN indexes on the array membership columns so "which flowers cover this hex"-style
+		// containment queries (the && and @> operators) use an index scan instead of a sequential
+		// scan. Named to match jsonbArrayColumns' naming (see jsonbarrays.go), which drops and
+		// recreates these same indexes when converting a column to jsonb.
+		"CREATE INDEX IF NOT EXISTS idx_pollen_hexes_flowers_gin ON pollen_hexes USING GIN (flowers)",
+		"CREATE INDEX IF NOT EXISTS idx_pollen_flowers_covered_hexes_gin ON pollen_flowers USING GIN (covered_hexes)",
+		"CREATE INDEX IF NOT EXISTS idx_pollen_rewards_coverage_gin ON pollen_rewards USING GIN (coverage)",
 	}
 	models = []interface{}{
 		Hex{},
 		Reward{},
 		Flower{},
+		SyncState{},
+		SyncRun{},
 	}
 	upsertClause = clause.OnConflict{
 		Columns:   []clause.Column{{Name: "id"}},
@@ -318,6 +1061,32 @@ func (r *Reward) TableName() string {
 	return tableNameReward
 }
 
+func (s *SyncState) TableName() string {
+	return tableNameSyncState
+}
+
+func (r *SyncRun) TableName() string {
+	return tableNameSyncRun
+}
+
+// getSyncState reads a previously stored sentinel/checkpoint value, returning "" if unset.
+func getSyncState(db *gorm.DB, key string) (string, error) {
+	var state SyncState
+	err := db.Where("key = ?", key).Take(&state).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	return state.Value, err
+}
+
+// setSyncState upserts a sentinel/checkpoint value.
+func setSyncState(db *gorm.DB, key, value string) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		UpdateAll: true,
+	}).Create(&SyncState{Key: key, Value: value}).Error
+}
+
 func quietLogger() logger.Interface {
 	return logger.New(
 		log.New(os.Stdout, "\r\n", log.LstdFlags),
@@ -329,14 +1098,25 @@ func quietLogger() logger.Interface {
 	)
 }
 
+// pollenAPIBase is the Pollen Mobile API's scheme+host, overridable via POLLEN_API_BASE so a
+// caching proxy or staging endpoint can be used without a new binary.
+var pollenAPIBase = envOrDefault("POLLEN_API_BASE", "https://api.pollenmobile.io")
+
+// envOrDefault returns the named environment variable, or def if it's unset or empty.
+func envOrDefault(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
 // Pollen API
 // (rate limit unknown)
-//
 var (
-	pollenAPIHexes   = "https://api.pollenmobile.io/explorer/hexes?partial=true&h3_hex_top="
-	pollenAPIHex     = "https://api.pollenmobile.io/explorer/hex?h3_hex="
-	pollenAPIFlowers = "https://api.pollenmobile.io/explorer/flowers"
-	pollenAPIRewards = "https://api.pollenmobile.io/explorer/device-rewards-all?device="
+	pollenAPIHexes   = pollenAPIBase + "/explorer/hexes?partial=true&h3_hex_top="
+	pollenAPIHex     = pollenAPIBase + "/explorer/hex?h3_hex="
+	pollenAPIFlowers = pollenAPIBase + "/explorer/flowers"
+	pollenAPIRewards = pollenAPIBase + "/explorer/device-rewards-all?device="
 	// Includes NYC and some parts of neighboring cities
 	pollenAPIHexesNYC = "852a1393fffffff,852a104bfffffff,852a1057fffffff,852a1063fffffff,852a100bfffffff,852a106ffffffff,852a13c3fffffff,852a107bfffffff,852a102ffffffff,852a1383fffffff,852a103bfffffff,852a1047fffffff,852a139bfffffff,852a106bfffffff,852a1077fffffff,852a12b7fffffff,852a102bfffffff,852a13d7fffffff,852a138bfffffff,852a1043fffffff,852a1397fffffff,852a104ffffffff,852a1003fffffff,852a1067fffffff,852a100ffffffff,852a12a7fffffff,852a1073fffffff,852a101bfffffff,852a13c7fffffff,852a12b3fffffff,852a13d3fffffff"
 	// Includes SF and some neighboring cities
@@ -364,28 +1144,28 @@ type (
 	// Pollen API Response for Grid-specific Information
 	HexItem struct {
 		Hex struct {
-			SignalStrength   int      `json:"signalStrength,string"`
-			Attach           int      `json:"attach,string"`
-			LastCovered      string   `json:"last_covered"`
-			LastPollenDrop   string   `json:"last_pollen_drop"`
-			Device           []string `json:"device"`
-			Time             string   `json:"time"`
-			H3HexTop         string   `json:"h3_hex_top"`
-			Flowers          []string `json:"flowers"`
-			FlowersContained []string `json:"flowers_contained"`
-			BountyReward     float64  `json:"bountyReward,string"`
-			H3Hex            string   `json:"h3_hex"`
-			Ping             float64  `json:"ping,string"`
-			LootBoxReward    int      `json:"lootBoxReward,string"`
-			DailyReward      int      `json:"dailyReward,string"`
-			Bounty           string   `json:"bounty"`
-			BountyTime       string   `json:"bounty_time"`
+			SignalStrength   FlexInt     `json:"signalStrength"`
+			Attach           FlexInt     `json:"attach"`
+			LastCovered      string      `json:"last_covered"`
+			LastPollenDrop   string      `json:"last_pollen_drop"`
+			Device           []string    `json:"device"`
+			Time             string      `json:"time"`
+			H3HexTop         string      `json:"h3_hex_top"`
+			Flowers          []string    `json:"flowers"`
+			FlowersContained []string    `json:"flowers_contained"`
+			BountyReward     FlexFloat64 `json:"bountyReward"`
+			H3Hex            string      `json:"h3_hex"`
+			Ping             FlexFloat64 `json:"ping"`
+			LootBoxReward    FlexInt     `json:"lootBoxReward"`
+			DailyReward      FlexInt     `json:"dailyReward"`
+			Bounty           string      `json:"bounty"`
+			BountyTime       string      `json:"bounty_time"`
 		} `json:"hex"`
 	}
 
 	// Pollen API Response for Flowers
 	FlowerListItem struct {
-		BountyRewards     int               `json:"bounty_rewards,string"`
+		BountyRewards     FlexInt           `json:"bounty_rewards"`
 		DisplayName       string            `json:"displayname"`
 		UpdateTime        string            `json:"update_time"`
 		DailyBeesSeen     []string          `json:"daily_bees_seen"`
@@ -394,31 +1174,31 @@ type (
 		WalletAddress     string            `json:"wallet_address"`
 		CoveredHexes      []string          `json:"covered_hexes"`
 		LastSeen          *string           `json:"last_seen"`
-		DailyAttaches     int               `json:"daily_attaches,string"`
+		DailyAttaches     FlexInt           `json:"daily_attaches"`
 		H3Hex             string            `json:"h3_hex"`
-		Active            int               `json:"attach,string"`
-		FlowerRewards     float64           `json:"flower_rewards,string"`
+		Active            FlexInt           `json:"attach"`
+		FlowerRewards     FlexFloat64       `json:"flower_rewards"`
 		DailyCoveredHexes []string          `json:"daily_covered_hexes"`
 		NFTAddress        string            `json:"nft_address"`
 		ID                string            `json:"flowerID"`
 		Nickname          string            `json:"nickname"`
-		FlowerAttaches    int               `json:"flower_attaches,string"`
+		FlowerAttaches    FlexInt           `json:"flower_attaches"`
 		DailyHBeesSeen    []string          `json:"daily_hbees_seen"`
-		DailyRewards      float64           `json:"daily_rewards,string"`
+		DailyRewards      FlexFloat64       `json:"daily_rewards"`
 		ImageURL          string            `json:"image_url"`
 		BeesSeen          map[string]string `json:"bees_seen"`
 	}
 
 	DeviceRewards    map[string][]DeviceRewardItem
 	DeviceRewardItem struct {
-		PCN      float64 `json:"PCN,string"`
-		PIC      float64 `json:"PIC,string"`
-		RSERatio float64 `json:"RSEratio,string"`
-		Client   string  `json:"client"`
+		PCN      FlexFloat64 `json:"PCN"`
+		PIC      FlexFloat64 `json:"PIC"`
+		RSERatio FlexFloat64 `json:"RSEratio"`
+		Client   string      `json:"client"`
 		// This should usually be []string, but some records mangle the API response
 		// with "[]". So we'll have to fix that manually.
 		Coverage          interface{} `json:"coverage"`
-		DailyPIC          float64     `json:"dailyPIC,string"`
+		DailyPIC          FlexFloat64 `json:"dailyPIC"`
 		Date              string      `json:"date"`
 		Device            string      `json:"device"`
 		DeviceType        string      `json:"device_type"`
@@ -430,25 +1210,23 @@ type (
 	}
 )
 
+// getAllHexes, getHexDetails, getAllFlowers, and getRewards are thin wrappers around the
+// package's PollenClient, kept as free functions so every existing call site stays unchanged;
+// swap pollenClient (see pollenclient.go) to point them at a fake in tests.
 func getAllHexes(area string) ([]HexListItem, error) {
-	return pollenAPICallWithRetries[[]HexListItem](pollenAPIHexes + area)
+	return pollenClient.GetAllHexes(area)
 }
 
 func getHexDetails(hex string) (HexItem, error) {
-	return pollenAPICallWithRetries[HexItem](pollenAPIHex + hex)
+	return pollenClient.GetHexDetails(hex)
 }
 
 func getAllFlowers() ([]FlowerListItem, error) {
-	return pollenAPICallWithRetries[[]FlowerListItem](pollenAPIFlowers)
+	return pollenClient.GetAllFlowers()
 }
 
 func getRewards(deviceName string) ([]DeviceRewardItem, error) {
-	rewardsByDate, err := pollenAPICallWithRetries[DeviceRewards](pollenAPIRewards + deviceName)
-	rewards := []DeviceRewardItem{}
-	for _, dailyRewards := range rewardsByDate {
-		rewards = append(rewards, dailyRewards...)
-	}
-	return rewards, err
+	return pollenClient.GetRewards(deviceName)
 }
 
 func pollenAPICallWithRetries[T interface{}](url string) (t T, err error) {
@@ -457,15 +1235,23 @@ func pollenAPICallWithRetries[T interface{}](url string) (t T, err error) {
 		if err == nil {
 			return t, err
 		}
+		if i < pollenRetries-1 {
+			atomic.AddInt64(&metrics.apiRetries, 1)
+		}
 		time.Sleep(pollenRetryWaitTime)
 	}
 	return t, err
 }
 
 func pollenAPICall[T interface{}](url string) (t T, err error) {
+	recordAPICall(url)
 	pollenRateLimit.Take()
+	start := time.Now()
+	defer func() {
+		recordAPILatency(url, time.Since(start).Milliseconds(), err != nil)
+	}()
 
-	cli := http.Client{Timeout: time.Second * 60}
+	cli := http.Client{Timeout: *httpTimeout}
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return t, err
@@ -473,23 +1259,43 @@ func pollenAPICall[T interface{}](url string) (t T, err error) {
 	for key, value := range pollenAPIHeaders {
 		req.Header.Set(key, value)
 	}
-	res, err := cli.Do(req)
+	res, err := doHTTP(&cli, req)
 	if err != nil {
 		return t, err
 	}
 	defer res.Body.Close()
-	err = json.NewDecoder(res.Body).Decode(&t)
-	return
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return t, err
+	}
+	if err := json.Unmarshal(body, &t); err != nil {
+		return t, &decodeError{Body: body, Err: err}
+	}
+	activeSchemaDrift.Check(endpointName(url), body)
+	return t, nil
+}
+
+// decodeError wraps a JSON decode failure with the raw response body, so call sites can write
+// it to the pollen_decode_failures dead letter table instead of just logging the parse error.
+type decodeError struct {
+	Body []byte
+	Err  error
+}
+
+func (e *decodeError) Error() string {
+	return fmt.Sprintf("decode response: %v", e.Err)
+}
+
+func (e *decodeError) Unwrap() error {
+	return e.Err
 }
 
 // OSM (Nominatim) API for reverse geocoding lat/lngs
 // (rate limit: 1/s)
-//
 var (
 	osmAPI = func(lat, lng float64) string {
-		return fmt.Sprintf("https://nominatim.openstreetmap.org/reverse?lat=%v&lon=%v&format=json", lat, lng)
+		return fmt.Sprintf("https://nominatim.openstreetmap.org/reverse?lat=%v&lon=%v&format=json&accept-language=%s", lat, lng, url.QueryEscape(*osmAcceptLanguage))
 	}
-	osmCache     = map[string]ReverseGeocode{}
 	osmUA        = "pollen"
 	osmRateLimit = ratelimit.New(1) // 1/s
 )
@@ -542,26 +1348,29 @@ func initGeocodeCache(db *gorm.DB) error {
 			return err
 		}
 		for _, record := range records {
-			osmCache[record.Hex] = record.ReverseGeocode
+			geocodeCache.Set(record.Hex, record.ReverseGeocode)
 		}
 	}
 	return nil
 }
 
 func reverseGeocode(hex string) (ReverseGeocode, error) {
-	if record, ok := osmCache[hex]; ok {
+	if record, ok := geocodeCache.Get(hex); ok {
+		atomic.AddInt64(&metrics.geocodeCacheHits, 1)
 		return record, nil
 	}
+	atomic.AddInt64(&metrics.geocodeCacheMisses, 1)
+	atomic.AddInt64(metrics.apiCalls["osm_reverse"], 1)
 	osmRateLimit.Take()
 
 	lat, lng := hexToLatLng(hex)
-	cli := http.Client{Timeout: time.Second * 60}
+	cli := http.Client{Timeout: *httpTimeout}
 	req, err := http.NewRequest(http.MethodGet, osmAPI(lat, lng), nil)
 	if err != nil {
 		return ReverseGeocode{}, err
 	}
 	req.Header.Set("user-agent", osmUA)
-	res, err := cli.Do(req)
+	res, err := doHTTP(&cli, req)
 	if err != nil {
 		return ReverseGeocode{}, err
 	}
@@ -581,7 +1390,7 @@ func reverseGeocode(hex string) (ReverseGeocode, error) {
 		Town:    place.Address.Town,
 		County:  place.Address.County,
 	}
-	osmCache[hex] = g
+	geocodeCache.Set(hex, g)
 	return g, nil
 }
 
@@ -591,25 +1400,112 @@ func hexToLatLng(hex string) (float64, float64) {
 	return latLng.Lat, latLng.Lng
 }
 
+// flowerGeocodeHex returns the hex a flower should be geocoded at. By default this is the
+// flower's own H3Hex (the API doesn't give us anything more precise), but --flower-geocode-resolution
+// lets operators pin geocoding to a specific resolution instead: coarser than H3Hex to group
+// nearby flowers onto the same cached address, or finer to approximate a street-level fix by
+// picking the cell's first child rather than its own (larger) center point.
+func flowerGeocodeHex(hex string) string {
+	if *geocodeResolution < 0 {
+		return hex
+	}
+	value, err := strconv.ParseInt(hex, 16, 64)
+	if err != nil {
+		return hex
+	}
+	cell := h3.Cell(value)
+	if !cell.IsValid() {
+		return hex
+	}
+	target := *geocodeResolution
+	current := cell.Resolution()
+	var adjusted h3.Cell
+	switch {
+	case target == current:
+		adjusted = cell
+	case target < current:
+		adjusted = cell.Parent(target)
+	default:
+		children := cell.Children(target)
+		if len(children) == 0 {
+			return hex
+		}
+		adjusted = children[0]
+	}
+	return adjusted.String()
+}
+
 // Helpers
 //
-func isValidHex(s string) bool {
-	hexes := strings.Split(s, ",")
-	for _, hex := range hexes {
-		if len(hex) != 15 {
-			return false
+
+// doHTTP performs req on cli, logging method/URL/status/latency (and optionally the
+// response body) to stderr when --debug-http is set. The API key is redacted from
+// any logged headers.
+func doHTTP(cli *http.Client, req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	res, err := cli.Do(req)
+	if !*debugHTTP {
+		return res, err
+	}
+	latency := time.Since(start)
+	if err != nil {
+		logProgress("[debug-http] %s %s -> error=%v (%s)\n", req.Method, req.URL, err, latency)
+		return res, err
+	}
+	logProgress("[debug-http] %s %s -> %d (%s) headers=%s\n", req.Method, req.URL, res.StatusCode, latency, redactedHeaders(req.Header))
+	if *debugHTTPBody {
+		body, readErr := io.ReadAll(res.Body)
+		res.Body.Close()
+		res.Body = io.NopCloser(bytes.NewReader(body))
+		if readErr != nil {
+			logProgress("[debug-http]   body: <error reading body: %v>\n", readErr)
+		} else {
+			logProgress("[debug-http]   body: %s\n", truncate(string(body), 2000))
 		}
-		_, err := strconv.ParseInt(hex, 16, 64)
-		if err != nil {
-			return false
+	}
+	return res, err
+}
+
+func redactedHeaders(h http.Header) string {
+	parts := make([]string, 0, len(h))
+	for k, v := range h {
+		val := strings.Join(v, ",")
+		if strings.EqualFold(k, "x-api-key") {
+			val = "REDACTED"
 		}
+		parts = append(parts, fmt.Sprintf("%s=%s", k, val))
 	}
-	return true
+	sort.Strings(parts)
+	return strings.Join(parts, " ")
 }
 
-func handleErr(err error) {
-	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		panic(err)
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "...(truncated)"
+}
+
+// pollenHexResolution is the H3 resolution the Pollen API expects for the top-level hex groups
+// passed on the command line (what the doc comment calls a "Level-5 hex").
+const pollenHexResolution = 5
+
+// validateHexGroup checks each comma-separated hex in s via the H3 library (not just its
+// string length), returning an error naming the specific hex that's invalid or the wrong
+// resolution, rather than silently letting it through to return an empty hex list.
+func validateHexGroup(s string) error {
+	for _, hex := range strings.Split(s, ",") {
+		value, err := strconv.ParseUint(hex, 16, 64)
+		if err != nil {
+			return fmt.Errorf("invalid hex %q: %v", hex, err)
+		}
+		cell := h3.Cell(value)
+		if !cell.IsValid() {
+			return fmt.Errorf("invalid hex %q: not a valid H3 cell", hex)
+		}
+		if res := cell.Resolution(); res != pollenHexResolution {
+			return fmt.Errorf("invalid hex %q: resolution %d, expected a level-%d hex", hex, res, pollenHexResolution)
+		}
 	}
+	return nil
 }
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// metricsAddr, when set, serves a Prometheus-style text exposition on /metrics for the
+// duration of the run (most useful in --watch mode, where the process stays up), so users can
+// see where time goes without waiting for the final summary line.
+var metricsAddr = flag.String("metrics-addr", "", "if set, serve Prometheus-style metrics on this address (e.g. :9090) for the life of the run")
+
+// metrics accumulates counters for the final run summary and /metrics endpoint. All fields are
+// updated with atomic ops since the hex sync pipeline fetches, geocodes, and writes concurrently.
+var metrics = struct {
+	geocodeCacheHits   int64
+	geocodeCacheMisses int64
+	apiCalls           map[string]*int64
+	apiRetries         int64
+	numericCoercions   int64
+	timeParseFailures  int64
+	hexDetailSkips     int64
+}{
+	apiCalls: map[string]*int64{
+		"pollen_hexes":   new(int64),
+		"pollen_hex":     new(int64),
+		"pollen_flowers": new(int64),
+		"pollen_rewards": new(int64),
+		"osm_reverse":    new(int64),
+	},
+}
+
+// recordAPICall increments the call counter for the endpoint whose URL prefix matches url.
+func recordAPICall(url string) {
+	switch {
+	case strings.HasPrefix(url, pollenAPIHexes):
+		atomic.AddInt64(metrics.apiCalls["pollen_hexes"], 1)
+	case strings.HasPrefix(url, pollenAPIHex):
+		atomic.AddInt64(metrics.apiCalls["pollen_hex"], 1)
+	case strings.HasPrefix(url, pollenAPIFlowers):
+		atomic.AddInt64(metrics.apiCalls["pollen_flowers"], 1)
+	case strings.HasPrefix(url, pollenAPIRewards):
+		atomic.AddInt64(metrics.apiCalls["pollen_rewards"], 1)
+	}
+}
+
+// geocodeCacheHitRate returns the fraction of reverseGeocode calls served from osmCache.
+func geocodeCacheHitRate() float64 {
+	hits := atomic.LoadInt64(&metrics.geocodeCacheHits)
+	misses := atomic.LoadInt64(&metrics.geocodeCacheMisses)
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
+// metricsSummary renders the counters as a single log-friendly string, appended to the run
+// summary line.
+func metricsSummary() string {
+	names := make([]string, 0, len(metrics.apiCalls))
+	for name := range metrics.apiCalls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	calls := make([]string, 0, len(names))
+	for _, name := range names {
+		calls = append(calls, fmt.Sprintf("%s=%d", name, atomic.LoadInt64(metrics.apiCalls[name])))
+	}
+	return fmt.Sprintf("geocode_cache_hit_rate=%.2f api_calls=[%s] api_retries=%d numeric_coercions=%d time_parse_failures=%d hex_detail_skips=%d",
+		geocodeCacheHitRate(), strings.Join(calls, ","), atomic.LoadInt64(&metrics.apiRetries), atomic.LoadInt64(&metrics.numericCoercions), atomic.LoadInt64(&metrics.timeParseFailures), atomic.LoadInt64(&metrics.hexDetailSkips))
+}
+
+// serveMetrics starts the /metrics HTTP server in the background if --metrics-addr is set, and
+// is a no-op otherwise. Errors are logged rather than aborting the run, since metrics are
+// diagnostic, not load-bearing.
+func serveMetrics() {
+	if *metricsAddr == "" {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "pollen_geocode_cache_hits %d\n", atomic.LoadInt64(&metrics.geocodeCacheHits))
+		fmt.Fprintf(w, "pollen_geocode_cache_misses %d\n", atomic.LoadInt64(&metrics.geocodeCacheMisses))
+		fmt.Fprintf(w, "pollen_api_retries_total %d\n", atomic.LoadInt64(&metrics.apiRetries))
+		fmt.Fprintf(w, "pollen_numeric_coercions_total %d\n", atomic.LoadInt64(&metrics.numericCoercions))
+		fmt.Fprintf(w, "pollen_time_parse_failures_total %d\n", atomic.LoadInt64(&metrics.timeParseFailures))
+		fmt.Fprintf(w, "pollen_hex_detail_skips_total %d\n", atomic.LoadInt64(&metrics.hexDetailSkips))
+		for name, count := range metrics.apiCalls {
+			fmt.Fprintf(w, "pollen_api_calls_total{endpoint=%q} %d\n", name, atomic.LoadInt64(count))
+		}
+		writeAPILatencyMetrics(w)
+		writeFreshnessMetrics(w)
+	})
+	go func() {
+		if err := http.ListenAndServe(*metricsAddr, mux); err != nil {
+			fmt.Printf("metrics server stopped: %v\n", err)
+		}
+	}()
+}
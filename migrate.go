@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	registerSubcommand("migrate-legacy", runMigrateLegacyCommand)
+}
+
+// runMigrateLegacyCommand brings a database created by a pre-synth-858 version of this tool
+// (no pollen_sync_state/pollen_sync_runs tables, no label column) up to the current schema
+// in-place, so long-time users don't have to re-crawl everything from scratch.
+func runMigrateLegacyCommand(args []string) int {
+	fs := flag.NewFlagSet("migrate-legacy", flag.ExitOnError)
+	fs.Parse(args)
+
+	db, err := openDB(os.Getenv("PG_URL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db connect failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	for _, model := range models {
+		if err := db.AutoMigrate(&model); err != nil {
+			fmt.Fprintf(os.Stderr, "migration failed: %v\n", err)
+			return ExitDBFailure
+		}
+	}
+	for _, idx := range indexes {
+		if err := db.Exec(idx).Error; err != nil {
+			fmt.Fprintf(os.Stderr, "migration failed: %v\n", err)
+			return ExitDBFailure
+		}
+	}
+	if err := applyCustomIndexes(db); err != nil {
+		fmt.Fprintf(os.Stderr, "migration failed: %v\n", err)
+		return ExitDBFailure
+	}
+	if err := applySlimSchema(db); err != nil {
+		fmt.Fprintf(os.Stderr, "migration failed: %v\n", err)
+		return ExitDBFailure
+	}
+	if err := applyRewardCompositeKey(db); err != nil {
+		fmt.Fprintf(os.Stderr, "migration failed: %v\n", err)
+		return ExitDBFailure
+	}
+	if err := applyStrictSchema(db); err != nil {
+		fmt.Fprintf(os.Stderr, "migration failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	// AutoMigrate adds the label column as NULL on pre-existing rows; backfill it to "" so it
+	// behaves the same as a freshly-synced row (unlabeled).
+	backfilled := 0
+	for _, tableName := range []string{tableNameFlower, tableNameHex, tableNameReward} {
+		n, err := backfillEmptyLabel(db, tableName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migration failed: %v\n", err)
+			return ExitDBFailure
+		}
+		backfilled += n
+	}
+
+	fmt.Printf("schema migrated, backfilled label on %d legacy rows\n", backfilled)
+	return ExitSuccess
+}
+
+// backfillEmptyLabel sets label to "" on any row where it's still NULL after AutoMigrate.
+func backfillEmptyLabel(db *gorm.DB, tableName string) (int, error) {
+	res := db.Table(tableName).Where("label IS NULL").Update("label", "")
+	return int(res.RowsAffected), res.Error
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// notifyChannel is the Postgres NOTIFY channel a successful sync's run stats are published on,
+// so downstream services (cache invalidation, materialized view refreshers) can react
+// immediately instead of polling pollen_sync_runs.
+var notifyChannel = flag.String("notify-channel", "pollen_sync", "Postgres NOTIFY channel to publish run stats on after a successful sync; empty disables it")
+
+// syncNotification is the JSON payload delivered with pg_notify.
+type syncNotification struct {
+	Label      string `json:"label"`
+	Result     string `json:"result"`
+	PhasesOK   int    `json:"phases_ok"`
+	PhasesRan  int    `json:"phases_ran"`
+	DurationMS int64  `json:"duration_ms"`
+	FinishedAt string `json:"finished_at"`
+}
+
+// notifySyncComplete publishes run's stats on notifyChannel if the run succeeded, a no-op if
+// --notify-channel is empty or the run had any phase errors.
+func notifySyncComplete(db *gorm.DB, run SyncRun, summary *runSummary) error {
+	if *notifyChannel == "" || summary.exitCode() != ExitSuccess {
+		return nil
+	}
+	finishedAt := time.Now()
+	payload, err := json.Marshal(syncNotification{
+		Label:      run.Label,
+		Result:     summary.status(),
+		PhasesOK:   summary.okPhases,
+		PhasesRan:  summary.ranPhases,
+		DurationMS: time.Since(summary.startedAt).Milliseconds(),
+		FinishedAt: finishedAt.Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+	return db.Exec("SELECT pg_notify(?, ?)", *notifyChannel, string(payload)).Error
+}
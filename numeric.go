@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strconv"
+	"sync/atomic"
+)
+
+// FlexInt and FlexFloat64 decode a field the Pollen API is supposed to send as a quoted numeric
+// string (what the old `,string` struct tags assumed) but which it occasionally sends as a bare
+// JSON number or an empty string instead. Both of those get coerced rather than failing the
+// whole decode, and metrics.numericCoercions counts how often it happened, so a growing count
+// signals an upstream API change worth a look even though no run ever aborts over it.
+type (
+	FlexInt     int64
+	FlexFloat64 float64
+)
+
+// unquote strips one layer of surrounding double quotes, if present, reporting whether it did.
+func unquote(b []byte) (string, bool) {
+	if len(b) >= 2 && b[0] == '"' && b[len(b)-1] == '"' {
+		return string(b[1 : len(b)-1]), true
+	}
+	return string(b), false
+}
+
+func (n *FlexInt) UnmarshalJSON(b []byte) error {
+	s, quoted := unquote(b)
+	if s == "" {
+		atomic.AddInt64(&metrics.numericCoercions, 1)
+		*n = 0
+		return nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return err
+	}
+	if !quoted {
+		atomic.AddInt64(&metrics.numericCoercions, 1)
+	}
+	*n = FlexInt(v)
+	return nil
+}
+
+func (n *FlexFloat64) UnmarshalJSON(b []byte) error {
+	s, quoted := unquote(b)
+	if s == "" {
+		atomic.AddInt64(&metrics.numericCoercions, 1)
+		*n = 0
+		return nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	if !quoted {
+		atomic.AddInt64(&metrics.numericCoercions, 1)
+	}
+	*n = FlexFloat64(v)
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFlexIntAcceptsQuotedUnquotedAndEmpty(t *testing.T) {
+	cases := []struct {
+		json string
+		want FlexInt
+	}{
+		{`"42"`, 42},
+		{`42`, 42},
+		{`""`, 0},
+	}
+	for _, c := range cases {
+		var n FlexInt
+		if err := json.Unmarshal([]byte(c.json), &n); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", c.json, err)
+		}
+		if n != c.want {
+			t.Errorf("Unmarshal(%s) = %v, want %v", c.json, n, c.want)
+		}
+	}
+}
+
+func TestFlexFloat64AcceptsQuotedUnquotedAndEmpty(t *testing.T) {
+	cases := []struct {
+		json string
+		want FlexFloat64
+	}{
+		{`"4.5"`, 4.5},
+		{`4.5`, 4.5},
+		{`""`, 0},
+	}
+	for _, c := range cases {
+		var n FlexFloat64
+		if err := json.Unmarshal([]byte(c.json), &n); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", c.json, err)
+		}
+		if n != c.want {
+			t.Errorf("Unmarshal(%s) = %v, want %v", c.json, n, c.want)
+		}
+	}
+}
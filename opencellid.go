@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"time"
+)
+
+// openCelliDHeader is the column order OpenCelliD's CSV importer expects.
+var openCelliDHeader = []string{"radio", "mcc", "net", "area", "cell", "unit", "lon", "lat", "range", "samples", "changeable", "created", "updated", "averageSignal"}
+
+// runExportOpenCelliD exports flower cell sites as an OpenCelliD-compatible CSV, so Pollen CBRS
+// radios can be contributed to or cross-checked against the community cell database.
+//
+// OpenCelliD identifies a cell by mcc/net/area/cell, none of which Pollen's API gives us for a
+// flower. --mcc and --net let the operator supply their CBRS network's real values; area/cell
+// are derived deterministically from the flower's own id (via a CRC32 hash) so re-exporting the
+// same flower always produces the same row instead of a new "cell" each time.
+func runExportOpenCelliD(args []string) int {
+	fs := flag.NewFlagSet("export opencellid", flag.ExitOnError)
+	out := fs.String("out", "cells.csv", "path to write the OpenCelliD CSV to")
+	region := fs.String("region", "", "only include flowers whose hex's region (H3HexTop) matches this value")
+	radio := fs.String("radio", "LTE", "OpenCelliD radio type to record (LTE, NR, UMTS, GSM, CDMA)")
+	mcc := fs.Int("mcc", 0, "mobile country code to record for every row")
+	net := fs.Int("net", 0, "mobile network code to record for every row")
+	rangeMeters := fs.Int("range", 1000, "coverage range in meters to record for every row")
+	fs.Parse(args)
+
+	db, err := openDB(os.Getenv("PG_URL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db connect failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	q := db.Table(tableNameFlower).Select("id", "lat", "lng", "h3_hex").Where("lat <> 0 OR lng <> 0")
+	if *region != "" {
+		q = q.Where("h3_hex IN (?)", db.Table(tableNameHex).Select("id").Where("h3_hex_top = ?", *region))
+	}
+	var flowers []Flower
+	if err := q.Find(&flowers).Error; err != nil {
+		fmt.Fprintf(os.Stderr, "query failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "creating %s failed: %v\n", *out, err)
+		return ExitRunFailure
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Write(openCelliDHeader)
+	now := fmt.Sprint(time.Now().Unix())
+	for _, flower := range flowers {
+		area, cell := openCelliDAreaCell(flower.ID)
+		w.Write([]string{
+			*radio,
+			fmt.Sprint(*mcc),
+			fmt.Sprint(*net),
+			fmt.Sprint(area),
+			fmt.Sprint(cell),
+			"0",
+			fmt.Sprintf("%f", flower.Lng),
+			fmt.Sprintf("%f", flower.Lat),
+			fmt.Sprint(*rangeMeters),
+			"1",
+			"1",
+			now,
+			now,
+			"0",
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		fmt.Fprintf(os.Stderr, "writing %s failed: %v\n", *out, err)
+		return ExitRunFailure
+	}
+
+	fmt.Printf("wrote %d cells to %s\n", len(flowers), *out)
+	return ExitSuccess
+}
+
+// openCelliDAreaCell deterministically derives OpenCelliD area/cell identifiers from a flower
+// id, splitting a CRC32 hash into two 16-bit halves so the same flower always maps to the same
+// area/cell pair across exports.
+func openCelliDAreaCell(flowerID string) (area, cell uint32) {
+	sum := crc32.ChecksumIEEE([]byte(flowerID))
+	return sum >> 16, sum & 0xFFFF
+}
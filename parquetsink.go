@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// parquetDir fans sync output out to a second sink alongside Postgres: one Parquet file per
+// table under this directory, written once per run, so a single API crawl can feed both the
+// operational database and a data lake without a second crawl doubling API load.
+var parquetDir = flag.String("parquet-dir", "", "directory to also write hex/flower/reward rows to as Parquet files; empty disables this sink")
+
+// activeParquetSink is the package-level data-lake sink every write path additionally writes
+// through, set once in runSync. It's nil (and every method a no-op) when --parquet-dir isn't set.
+var activeParquetSink *parquetSink
+
+// parquetSink holds one open Parquet writer per table for the duration of a run. A nil
+// *parquetSink (returned when --parquet-dir is unset) makes every WriteX/Close call a no-op.
+type parquetSink struct {
+	hexFile      *os.File
+	flowerFile   *os.File
+	rewardFile   *os.File
+	hexWriter    *parquet.GenericWriter[Hex]
+	flowerWriter *parquet.GenericWriter[Flower]
+	rewardWriter *parquet.GenericWriter[Reward]
+}
+
+// openParquetSink opens (or truncates) one Parquet file per table under --parquet-dir, a no-op
+// returning nil if the flag isn't set.
+func openParquetSink() (*parquetSink, error) {
+	if *parquetDir == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(*parquetDir, 0o755); err != nil {
+		return nil, err
+	}
+	ps := &parquetSink{}
+	var err error
+	if ps.hexFile, err = os.Create(filepath.Join(*parquetDir, tableNameHex+".parquet")); err != nil {
+		return nil, err
+	}
+	if ps.flowerFile, err = os.Create(filepath.Join(*parquetDir, tableNameFlower+".parquet")); err != nil {
+		return nil, err
+	}
+	if ps.rewardFile, err = os.Create(filepath.Join(*parquetDir, tableNameReward+".parquet")); err != nil {
+		return nil, err
+	}
+	ps.hexWriter = parquet.NewGenericWriter[Hex](ps.hexFile)
+	ps.flowerWriter = parquet.NewGenericWriter[Flower](ps.flowerFile)
+	ps.rewardWriter = parquet.NewGenericWriter[Reward](ps.rewardFile)
+	return ps, nil
+}
+
+func (ps *parquetSink) WriteHex(row Hex) error {
+	if ps == nil {
+		return nil
+	}
+	_, err := ps.hexWriter.Write([]Hex{row})
+	return err
+}
+
+func (ps *parquetSink) WriteFlowers(rows []Flower) error {
+	if ps == nil || len(rows) == 0 {
+		return nil
+	}
+	_, err := ps.flowerWriter.Write(rows)
+	return err
+}
+
+func (ps *parquetSink) WriteRewards(rows []Reward) error {
+	if ps == nil || len(rows) == 0 {
+		return nil
+	}
+	_, err := ps.rewardWriter.Write(rows)
+	return err
+}
+
+// Close flushes and closes every open writer/file, reporting the first error encountered.
+func (ps *parquetSink) Close() error {
+	if ps == nil {
+		return nil
+	}
+	var firstErr error
+	for _, step := range []func() error{
+		ps.hexWriter.Close, ps.flowerWriter.Close, ps.rewardWriter.Close,
+		ps.hexFile.Close, ps.flowerFile.Close, ps.rewardFile.Close,
+	} {
+		if err := step(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("closing parquet sink: %w", err)
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// pipelineBufferSize is the channel depth between stages, enough to smooth over the latency
+// difference between the Pollen fetch and Nominatim geocode rate limits without unbounded
+// buffering.
+const pipelineBufferSize = 16
+
+type hexFetched struct {
+	hex     HexListItem
+	details HexItem
+}
+
+type hexGeocoded struct {
+	hexFetched
+	geo ReverseGeocode
+}
+
+// hexFingerprint is the list-level fields the hex list endpoint returns alongside each hex,
+// cheap enough to compare against the stored row without fetching full hex details.
+type hexFingerprint struct {
+	Covered     int
+	FlowerCount int
+}
+
+// loadHexFingerprints fetches the stored Covered/FlowerCount for every hex in hexes, so the
+// fetch stage can skip hexes whose list-level data hasn't changed since the last sync.
+func loadHexFingerprints(db *gorm.DB, hexes []HexListItem) (map[string]hexFingerprint, error) {
+	ids := make([]string, len(hexes))
+	for i, h := range hexes {
+		ids[i] = h.ID
+	}
+	var rows []struct {
+		ID          string
+		Covered     int
+		FlowerCount int
+	}
+	if err := db.Table(tableNameHex).Select("id, covered, flower_count").Where("id IN ?", ids).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	fingerprints := make(map[string]hexFingerprint, len(rows))
+	for _, r := range rows {
+		fingerprints[r.ID] = hexFingerprint{Covered: r.Covered, FlowerCount: r.FlowerCount}
+	}
+	return fingerprints, nil
+}
+
+// syncHexesPipelined syncs hexes as a three-stage pipeline (API fetch -> geocode -> DB write)
+// connected by channels, so the three independently rate-limited resources overlap instead of
+// each hex executing all three steps serially.
+func syncHexesPipelined(db *gorm.DB, hexes []HexListItem) (regionSyncStats, error) {
+	fingerprints, err := loadHexFingerprints(db, hexes)
+	if err != nil {
+		return regionSyncStats{}, err
+	}
+
+	fetched := make(chan hexFetched, pipelineBufferSize)
+	geocoded := make(chan hexGeocoded, pipelineBufferSize)
+	errs := make(chan error, 2)
+
+	go func() {
+		defer close(fetched)
+		for _, hex := range hexes {
+			if deadlineExceeded() || requestBudgetExceeded() {
+				return
+			}
+			if !*force && fingerprints[hex.ID] == (hexFingerprint{Covered: hex.Covered, FlowerCount: hex.FlowerCount}) {
+				atomic.AddInt64(&metrics.hexDetailSkips, 1)
+				continue
+			}
+			details, err := getHexDetails(hex.ID)
+			if err != nil {
+				var derr *decodeError
+				if errors.As(err, &derr) {
+					if ferr := writeDecodeFailure(db, "hex", hex.ID, derr.Body, derr.Err); ferr != nil {
+						errs <- ferr
+						return
+					}
+					continue
+				}
+				// Retries are already exhausted by getHexDetails (see pollenAPICallWithRetries), so
+				// queue this hex for the next run instead of aborting everything still left to sync.
+				if ferr := enqueueRetry(db, "hex", hex.ID, err); ferr != nil {
+					errs <- ferr
+					return
+				}
+				continue
+			}
+			fetched <- hexFetched{hex: hex, details: details}
+		}
+	}()
+
+	go func() {
+		defer close(geocoded)
+		for f := range fetched {
+			geo, err := reverseGeocode(f.hex.ID)
+			if err != nil {
+				errs <- err
+				return
+			}
+			geocoded <- hexGeocoded{hexFetched: f, geo: geo}
+		}
+	}()
+
+	written, covered := 0, 0
+	flowerIDs := map[string]bool{}
+	for g := range geocoded {
+		if err := writeHex(db, g.hex, g.details, g.geo); err != nil {
+			return regionSyncStats{}, err
+		}
+		if err := dequeueRetry(db, "hex", g.hex.ID); err != nil {
+			return regionSyncStats{}, err
+		}
+		written++
+		if g.hex.Covered > 0 {
+			covered++
+		}
+		for _, id := range g.details.Hex.Flowers {
+			flowerIDs[id] = true
+		}
+		if written%100 == 0 {
+			logProgress("Hex progress: %v / %v\n", written, len(hexes))
+		}
+	}
+
+	select {
+	case err := <-errs:
+		return regionSyncStats{}, err
+	default:
+		skipped := int(atomic.LoadInt64(&metrics.hexDetailSkips))
+		if written+skipped < len(hexes) {
+			logProgress("--max-duration or --max-requests reached, checkpointing hex sync at %d/%d\n", written, len(hexes))
+		}
+		newFlowers, err := newFlowerCount(db, flowerIDs)
+		if err != nil {
+			return regionSyncStats{}, err
+		}
+		return regionSyncStats{HexesFetched: written, Covered: covered, NewFlowers: newFlowers}, nil
+	}
+}
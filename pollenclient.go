@@ -0,0 +1,64 @@
+package main
+
+import "encoding/json"
+
+// PollenClient is every call this tool makes to the Pollen Mobile API, pulled out as an
+// interface so tests can swap in a fake seeded from fixtures instead of hitting the real
+// service. pollenClient is the package-level instance everything else goes through.
+type PollenClient interface {
+	GetAllHexes(area string) ([]HexListItem, error)
+	GetHexDetails(hex string) (HexItem, error)
+	GetAllFlowers() ([]FlowerListItem, error)
+	GetRewards(deviceName string) ([]DeviceRewardItem, error)
+}
+
+var pollenClient PollenClient = pollenHTTPClient{}
+
+// pollenHTTPClient is the production PollenClient, talking to the real Pollen Mobile API
+// through pollenAPICallWithRetries. GetHexDetails and GetAllFlowers additionally go through the
+// on-disk cache (see httpcache.go), since those are the two endpoints steady-state runs fetch
+// over and over for data that rarely changes within --cache-ttl.
+type pollenHTTPClient struct{}
+
+func (pollenHTTPClient) GetAllHexes(area string) ([]HexListItem, error) {
+	return pollenAPICallWithRetries[[]HexListItem](pollenAPIHexes + area)
+}
+
+func (pollenHTTPClient) GetHexDetails(hex string) (HexItem, error) {
+	url := pollenAPIHex + hex
+	if cached, ok := cacheGet(url); ok {
+		var t HexItem
+		if err := json.Unmarshal(cached, &t); err == nil {
+			return t, nil
+		}
+	}
+	t, err := pollenAPICallWithRetries[HexItem](url)
+	if err == nil {
+		cachePut(url, t)
+	}
+	return t, err
+}
+
+func (pollenHTTPClient) GetAllFlowers() ([]FlowerListItem, error) {
+	url := pollenAPIFlowers
+	if cached, ok := cacheGet(url); ok {
+		var t []FlowerListItem
+		if err := json.Unmarshal(cached, &t); err == nil {
+			return t, nil
+		}
+	}
+	t, err := pollenAPICallWithRetries[[]FlowerListItem](url)
+	if err == nil {
+		cachePut(url, t)
+	}
+	return t, err
+}
+
+func (pollenHTTPClient) GetRewards(deviceName string) ([]DeviceRewardItem, error) {
+	rewardsByDate, err := pollenAPICallWithRetries[DeviceRewards](pollenAPIRewards + deviceName)
+	rewards := []DeviceRewardItem{}
+	for _, dailyRewards := range rewardsByDate {
+		rewards = append(rewards, dailyRewards...)
+	}
+	return rewards, err
+}
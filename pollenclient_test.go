@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+// fakePollenClient is an in-memory PollenClient backed by fixtures, letting tests exercise the
+// sync logic without making real HTTP calls. Errs, when set for a given key, is returned by the
+// matching method instead of fixture data, so failure paths (dead-lettering, checkpointing,
+// --max-requests) can be tested too.
+//
+// Note: this only covers the client layer. A true end-to-end test of sync against a live
+// Postgres instance needs a database the test can spin up (e.g. via Docker), which isn't
+// available in every environment this repo is built in, so it's left out rather than faked.
+type fakePollenClient struct {
+	Hexes   map[string][]HexListItem
+	Details map[string]HexItem
+	Flowers []FlowerListItem
+	Rewards map[string][]DeviceRewardItem
+	Errs    map[string]error
+}
+
+func (f *fakePollenClient) GetAllHexes(area string) ([]HexListItem, error) {
+	if err, ok := f.Errs[area]; ok {
+		return nil, err
+	}
+	return f.Hexes[area], nil
+}
+
+func (f *fakePollenClient) GetHexDetails(hex string) (HexItem, error) {
+	if err, ok := f.Errs[hex]; ok {
+		return HexItem{}, err
+	}
+	return f.Details[hex], nil
+}
+
+func (f *fakePollenClient) GetAllFlowers() ([]FlowerListItem, error) {
+	return f.Flowers, nil
+}
+
+func (f *fakePollenClient) GetRewards(deviceName string) ([]DeviceRewardItem, error) {
+	if err, ok := f.Errs[deviceName]; ok {
+		return nil, err
+	}
+	return f.Rewards[deviceName], nil
+}
+
+var _ PollenClient = &fakePollenClient{}
+
+// withFakePollenClient swaps pollenClient for fake for the life of the test.
+func withFakePollenClient(t *testing.T, fake *fakePollenClient) {
+	t.Helper()
+	previous := pollenClient
+	pollenClient = fake
+	t.Cleanup(func() { pollenClient = previous })
+}
+
+func TestGetAllHexesUsesFakeClient(t *testing.T) {
+	fake := &fakePollenClient{
+		Hexes: map[string][]HexListItem{
+			"852a1393fffffff": {{ID: "8a2a1393fffffff", FlowerCount: 2, Covered: 1}},
+		},
+	}
+	withFakePollenClient(t, fake)
+
+	hexes, err := getAllHexes("852a1393fffffff")
+	if err != nil {
+		t.Fatalf("getAllHexes: %v", err)
+	}
+	if len(hexes) != 1 || hexes[0].ID != "8a2a1393fffffff" {
+		t.Fatalf("getAllHexes returned %+v, want fixture data", hexes)
+	}
+}
+
+func TestGetRewardsPropagatesClientError(t *testing.T) {
+	wantErr := errors.New("malformed rewards payload")
+	fake := &fakePollenClient{Errs: map[string]error{"device-1": wantErr}}
+	withFakePollenClient(t, fake)
+
+	if _, err := getRewards("device-1"); !errors.Is(err, wantErr) {
+		t.Fatalf("getRewards error = %v, want %v", err, wantErr)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PollenDrop is an append-only event log of a hex's last_pollen_drop value changing, since Hex
+// itself only ever holds the most recent value and drop frequency per hex can't be analyzed
+// historically from that alone.
+type PollenDrop struct {
+	ID         uint   `gorm:"primaryKey"`
+	HexID      string `gorm:"index"`
+	DroppedAt  string
+	RecordedAt time.Time
+}
+
+var tableNamePollenDrop = "pollen_drops"
+
+func (p *PollenDrop) TableName() string {
+	return tableNamePollenDrop
+}
+
+func init() {
+	models = append(models, PollenDrop{})
+}
+
+// recordPollenDrop inserts a pollen_drops event when newDrop differs from hexID's previously
+// stored last_pollen_drop. A hex seen for the first time, or an empty newDrop, isn't a "change"
+// and records nothing.
+func recordPollenDrop(db *gorm.DB, hexID, newDrop string) error {
+	if newDrop == "" {
+		return nil
+	}
+	var previous Hex
+	err := db.Select("last_pollen_drop").Where("id = ?", hexID).Take(&previous).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if previous.LastPollenDrop == newDrop {
+		return nil
+	}
+	return db.Create(&PollenDrop{HexID: hexID, DroppedAt: newDrop, RecordedAt: time.Now()}).Error
+}
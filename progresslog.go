@@ -0,0 +1,15 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// logProgress writes a human-readable progress/diagnostic line to stderr, exactly like
+// fmt.Printf would to stdout. Everything this tool actually produces for downstream consumption
+// (the final run summary line from finish, export's files, report's output, compare's diff)
+// stays on stdout via fmt.Println/fmt.Printf, so `pollen sync 2>/dev/null` or piping a
+// data-producing subcommand into jq/awk never sees this chatter mixed in.
+func logProgress(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format, args...)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	registerSubcommand("refresh-views", runRefreshViewsCommand)
+}
+
+// refreshMaterializedViews refreshes every view in materializedViewNames concurrently, logging
+// how long each one took, so one slow view doesn't serialize behind the others and a run never
+// leaves a view stale without anyone noticing.
+func refreshMaterializedViews(db *gorm.DB) error {
+	var wg sync.WaitGroup
+	errs := make(chan error, len(materializedViewNames))
+	for _, name := range materializedViewNames {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			start := time.Now()
+			if err := db.Exec("REFRESH MATERIALIZED VIEW " + name).Error; err != nil {
+				errs <- fmt.Errorf("refreshing %s: %w", name, err)
+				return
+			}
+			logProgress("refreshed %s in %s\n", name, time.Since(start).Round(time.Millisecond))
+		}(name)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runRefreshViewsCommand refreshes every materialized view on demand, for operators who want to
+// force a refresh outside of a full sync (e.g. right before pulling up a dashboard).
+func runRefreshViewsCommand(args []string) int {
+	db, err := openDB(os.Getenv("PG_URL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db connect failed: %v\n", err)
+		return ExitDBFailure
+	}
+	if err := refreshMaterializedViews(db); err != nil {
+		fmt.Fprintf(os.Stderr, "refresh failed: %v\n", err)
+		return ExitRunFailure
+	}
+	return ExitSuccess
+}
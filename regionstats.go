@@ -0,0 +1,67 @@
+package main
+
+import (
+	"gorm.io/gorm"
+)
+
+// SyncRunRegion records one hex group's ("region's") stats for one sync run, so a deployment
+// syncing several disjoint regions in one invocation can see per-region coverage and flower
+// growth instead of only the combined totals summary.String() reports.
+type SyncRunRegion struct {
+	ID           uint `gorm:"primaryKey"`
+	SyncRunID    uint `gorm:"index"`
+	Region       string
+	HexesFetched int
+	Covered      int
+	NewFlowers   int
+}
+
+var tableNameSyncRunRegion = "pollen_sync_run_regions"
+
+func (r *SyncRunRegion) TableName() string {
+	return tableNameSyncRunRegion
+}
+
+func init() {
+	models = append(models, SyncRunRegion{})
+}
+
+// regionSyncStats is the per-region tally syncHexes hands back to its caller: how many hexes it
+// fetched, how many were covered, and how many flower IDs it saw that pollen_flowers didn't
+// already know about.
+type regionSyncStats struct {
+	Region       string
+	HexesFetched int
+	Covered      int
+	NewFlowers   int
+}
+
+// recordRegionStats persists stats against runID, called once per hex group right after
+// syncHexes returns.
+func recordRegionStats(db *gorm.DB, runID uint, stats regionSyncStats) error {
+	row := SyncRunRegion{
+		SyncRunID:    runID,
+		Region:       stats.Region,
+		HexesFetched: stats.HexesFetched,
+		Covered:      stats.Covered,
+		NewFlowers:   stats.NewFlowers,
+	}
+	return db.Create(&row).Error
+}
+
+// newFlowerCount counts how many of flowerIDs aren't already present in pollen_flowers, i.e. how
+// many of the flowers this hex group's hexes reference are new to this sync's view of the world.
+func newFlowerCount(db *gorm.DB, flowerIDs map[string]bool) (int, error) {
+	if len(flowerIDs) == 0 {
+		return 0, nil
+	}
+	ids := make([]string, 0, len(flowerIDs))
+	for id := range flowerIDs {
+		ids = append(ids, id)
+	}
+	var known []string
+	if err := db.Table(tableNameFlower).Where("id IN ?", ids).Pluck("id", &known).Error; err != nil {
+		return 0, err
+	}
+	return len(ids) - len(known), nil
+}
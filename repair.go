@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	registerSubcommand("repair", runRepairCommand)
+}
+
+// runRepairCommand finds rewards referencing a device with no matching flower row, and reward
+// coverage referencing a hex with no matching hex row, and tries to resolve each by fetching the
+// missing entity from the API, reporting anything it couldn't. Useful on a database old enough to
+// predate --strict-schema's FK constraints, where orphans like these can otherwise accumulate
+// silently.
+func runRepairCommand(args []string) int {
+	fs := flag.NewFlagSet("repair", flag.ExitOnError)
+	fs.Parse(args)
+
+	db, err := openDB(os.Getenv("PG_URL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db connect failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	orphanDevices, err := orphanRewardDevices(db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "query failed: %v\n", err)
+		return ExitDBFailure
+	}
+	logProgress("Found %d reward device(s) with no matching flower\n", len(orphanDevices))
+	unresolvedDevices := repairOrphanDevices(db, orphanDevices)
+
+	orphanHexes, err := orphanCoverageHexes(db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "query failed: %v\n", err)
+		return ExitDBFailure
+	}
+	logProgress("Found %d coverage hex(es) with no matching hex\n", len(orphanHexes))
+	unresolvedHexes := repairOrphanHexes(db, orphanHexes)
+
+	if len(unresolvedDevices) > 0 {
+		logProgress("Could not resolve %d device(s): %s\n", len(unresolvedDevices), strings.Join(unresolvedDevices, ", "))
+	}
+	if len(unresolvedHexes) > 0 {
+		logProgress("Could not resolve %d hex(es): %s\n", len(unresolvedHexes), strings.Join(unresolvedHexes, ", "))
+	}
+	if len(unresolvedDevices) > 0 || len(unresolvedHexes) > 0 {
+		return ExitPartialSuccess
+	}
+	logProgress("repair complete, no unresolved orphans\n")
+	return ExitSuccess
+}
+
+// orphanRewardDevices returns the distinct reward device values with no matching flower row.
+func orphanRewardDevices(db *gorm.DB) ([]string, error) {
+	var devices []string
+	err := db.Table(tableNameReward).
+		Distinct("device").
+		Where("device <> '' AND device NOT IN (SELECT id FROM "+tableNameFlower+")").
+		Pluck("device", &devices).Error
+	return devices, err
+}
+
+// orphanCoverageHexes returns the distinct reward coverage hex IDs with no matching hex row.
+func orphanCoverageHexes(db *gorm.DB) ([]string, error) {
+	var hexes []string
+	err := db.Table(tableNameRewardCoverage).
+		Distinct("hex_id").
+		Where("hex_id NOT IN (SELECT id FROM "+tableNameHex+")").
+		Pluck("hex_id", &hexes).Error
+	return hexes, err
+}
+
+// repairOrphanDevices re-syncs the full flower list once, since the Pollen API only offers a
+// list endpoint for flowers (no per-device fetch), then reports which devices are still missing
+// afterward.
+func repairOrphanDevices(db *gorm.DB, devices []string) []string {
+	if len(devices) == 0 {
+		return nil
+	}
+	if err := syncFlowers(db); err != nil {
+		fmt.Fprintf(os.Stderr, "refetching flowers failed: %v\n", err)
+		return devices
+	}
+	var unresolved []string
+	for _, d := range devices {
+		var count int64
+		db.Table(tableNameFlower).Where("id = ?", d).Count(&count)
+		if count == 0 {
+			unresolved = append(unresolved, d)
+		}
+	}
+	return unresolved
+}
+
+// repairOrphanHexes fetches each hex directly by ID via upsertHex, since the Pollen API (unlike
+// flowers) supports per-hex detail lookups, reporting any that still fail.
+func repairOrphanHexes(db *gorm.DB, hexIDs []string) []string {
+	var unresolved []string
+	for _, id := range hexIDs {
+		if err := upsertHex(db, HexListItem{ID: id}); err != nil {
+			fmt.Fprintf(os.Stderr, "resolving hex %s failed: %v\n", id, err)
+			unresolved = append(unresolved, id)
+		}
+	}
+	return unresolved
+}
@@ -0,0 +1,460 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"html"
+	"html/template"
+	"os"
+	"time"
+)
+
+func init() {
+	registerSubcommand("report", runReportCommand)
+}
+
+// runReportCommand dispatches `report <subcommand>`.
+func runReportCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: report wallet|uptime|bounties|map|heatmap ...")
+		return ExitRunFailure
+	}
+	switch args[0] {
+	case "wallet":
+		return runReportWallet(args[1:])
+	case "uptime":
+		return runReportUptime(args[1:])
+	case "bounties":
+		return runReportBounties(args[1:])
+	case "map":
+		return runReportMap(args[1:])
+	case "heatmap":
+		return runReportHeatmap(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown report subcommand %q\n", args[0])
+		return ExitRunFailure
+	}
+}
+
+// walletEarningsRow is one day of earnings for a wallet.
+type walletEarningsRow struct {
+	Date string  `json:"date"`
+	PCN  float64 `json:"pcn"`
+	PIC  float64 `json:"pic"`
+}
+
+// runReportWallet prints a per-day and total PCN/PIC earnings report for a wallet, scoped to
+// an optional date range, for tax/accounting purposes.
+func runReportWallet(args []string) int {
+	fs := flag.NewFlagSet("report wallet", flag.ExitOnError)
+	from := fs.String("from", "", "only include rewards on or after this date (YYYY-MM-DD)")
+	to := fs.String("to", "", "only include rewards on or before this date (YYYY-MM-DD)")
+	format := fs.String("format", "table", "output format: table, csv, or json")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: report wallet <addr> [--from 2023-01-01] [--to 2023-12-31] [--format table|csv|json]")
+		return ExitRunFailure
+	}
+	wallet := fs.Arg(0)
+
+	db, err := openDB(os.Getenv("PG_URL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db connect failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	q := db.Table(tableNameReward).
+		Select("date::text AS date, SUM(pcn) AS pcn, SUM(pic) AS pic").
+		Where("wallet = ?", wallet).
+		Group("date").
+		Order("date ASC")
+	if *from != "" {
+		q = q.Where("date >= ?", *from)
+	}
+	if *to != "" {
+		q = q.Where("date <= ?", *to)
+	}
+	var rows []walletEarningsRow
+	if err := q.Find(&rows).Error; err != nil {
+		fmt.Fprintf(os.Stderr, "query failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	var totalPCN, totalPIC float64
+	for _, r := range rows {
+		totalPCN += r.PCN
+		totalPIC += r.PIC
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(struct {
+			Wallet   string              `json:"wallet"`
+			Days     []walletEarningsRow `json:"days"`
+			TotalPCN float64             `json:"total_pcn"`
+			TotalPIC float64             `json:"total_pic"`
+		}{wallet, rows, totalPCN, totalPIC})
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"date", "pcn", "pic"})
+		for _, r := range rows {
+			w.Write([]string{r.Date, fmt.Sprintf("%.6f", r.PCN), fmt.Sprintf("%.6f", r.PIC)})
+		}
+		w.Write([]string{"TOTAL", fmt.Sprintf("%.6f", totalPCN), fmt.Sprintf("%.6f", totalPIC)})
+		w.Flush()
+	default:
+		fmt.Printf("Earnings for wallet %s\n", wallet)
+		fmt.Printf("%-12s %12s %12s\n", "Date", "PCN", "PIC")
+		for _, r := range rows {
+			fmt.Printf("%-12s %12.4f %12.4f\n", r.Date, r.PCN, r.PIC)
+		}
+		fmt.Printf("%-12s %12.4f %12.4f\n", "TOTAL", totalPCN, totalPIC)
+	}
+	return ExitSuccess
+}
+
+// flowerUptimeRow is one flower's uptime for a report month.
+type flowerUptimeRow struct {
+	FlowerID    string  `json:"flower_id"`
+	DisplayName string  `json:"display_name"`
+	DaysSeen    int     `json:"days_seen"`
+	DaysInMonth int     `json:"days_in_month"`
+	UptimePct   float64 `json:"uptime_pct"`
+}
+
+// runReportUptime prints a monthly per-flower uptime report, derived from how many distinct
+// days pollen_flower_history has a snapshot for the flower out of the days in that month, which
+// fleet operators use for revenue-share agreements with site hosts.
+func runReportUptime(args []string) int {
+	fs := flag.NewFlagSet("report uptime", flag.ExitOnError)
+	format := fs.String("format", "table", "output format: table, csv, or html")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: report uptime <YYYY-MM> [--format table|csv|html]")
+		return ExitRunFailure
+	}
+	month := fs.Arg(0)
+	start, err := time.Parse("2006-01", month)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid month %q, want YYYY-MM: %v\n", month, err)
+		return ExitRunFailure
+	}
+	end := start.AddDate(0, 1, 0)
+	daysInMonth := int(end.Sub(start).Hours() / 24)
+
+	db, err := openDB(os.Getenv("PG_URL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db connect failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	displayNames := map[string]string{}
+	var flowers []Flower
+	if err := db.Table(tableNameFlower).Select("id, display_name").Find(&flowers).Error; err != nil {
+		fmt.Fprintf(os.Stderr, "query failed: %v\n", err)
+		return ExitDBFailure
+	}
+	for _, f := range flowers {
+		displayNames[f.ID] = f.DisplayName
+	}
+
+	var rows []flowerUptimeRow
+	err = db.Table(tableNameFlowerHistory).
+		Select("flower_id, COUNT(DISTINCT DATE(recorded_at)) AS days_seen").
+		Where("recorded_at >= ? AND recorded_at < ?", start, end).
+		Group("flower_id").
+		Order("flower_id ASC").
+		Find(&rows).Error
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "query failed: %v\n", err)
+		return ExitDBFailure
+	}
+	for i := range rows {
+		rows[i].DisplayName = displayNames[rows[i].FlowerID]
+		rows[i].DaysInMonth = daysInMonth
+		rows[i].UptimePct = 100 * float64(rows[i].DaysSeen) / float64(daysInMonth)
+	}
+
+	switch *format {
+	case "html":
+		fmt.Println("<table border=\"1\"><tr><th>Flower</th><th>Name</th><th>Days Up</th><th>Uptime %</th></tr>")
+		for _, r := range rows {
+			fmt.Printf("<tr><td>%s</td><td>%s</td><td>%d/%d</td><td>%.1f%%</td></tr>\n",
+				html.EscapeString(r.FlowerID), html.EscapeString(r.DisplayName), r.DaysSeen, r.DaysInMonth, r.UptimePct)
+		}
+		fmt.Println("</table>")
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"flower_id", "display_name", "days_seen", "days_in_month", "uptime_pct"})
+		for _, r := range rows {
+			w.Write([]string{r.FlowerID, r.DisplayName, fmt.Sprint(r.DaysSeen), fmt.Sprint(r.DaysInMonth), fmt.Sprintf("%.2f", r.UptimePct)})
+		}
+		w.Flush()
+	default:
+		fmt.Printf("Uptime report for %s\n", month)
+		fmt.Printf("%-20s %-24s %12s %10s\n", "Flower", "Name", "Days Up", "Uptime")
+		for _, r := range rows {
+			fmt.Printf("%-20s %-24s %6d/%-5d %9.1f%%\n", r.FlowerID, r.DisplayName, r.DaysSeen, r.DaysInMonth, r.UptimePct)
+		}
+	}
+	return ExitSuccess
+}
+
+// bountyRow is one hex's current bounty, as carried on pollen_hexes.
+type bountyRow struct {
+	HexID      string `json:"hex_id"`
+	Region     string `json:"region"`
+	Bounty     string `json:"bounty"`
+	BountyTime string `json:"bounty_time"`
+}
+
+// runReportBounties exports the active/upcoming bounties across hexes, optionally scoped to a
+// region (H3HexTop), as either an iCalendar feed or a JSON list, so drive-testing can be planned
+// around them.
+func runReportBounties(args []string) int {
+	fs := flag.NewFlagSet("report bounties", flag.ExitOnError)
+	region := fs.String("region", "", "only include hexes whose region (H3HexTop) matches this value")
+	format := fs.String("format", "json", "output format: json or ics")
+	fs.Parse(args)
+
+	db, err := openDB(os.Getenv("PG_URL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db connect failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	q := db.Table(tableNameHex).
+		Select("id AS hex_id, h3_hex_top AS region, bounty, bounty_time").
+		Where("bounty IS NOT NULL AND bounty <> ''").
+		Order("bounty_time ASC")
+	if *region != "" {
+		q = q.Where("h3_hex_top = ?", *region)
+	}
+	var rows []bountyRow
+	if err := q.Find(&rows).Error; err != nil {
+		fmt.Fprintf(os.Stderr, "query failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	switch *format {
+	case "ics":
+		fmt.Println("BEGIN:VCALENDAR")
+		fmt.Println("VERSION:2.0")
+		fmt.Println("PRODID:-//pollen//bounties//EN")
+		for _, r := range rows {
+			start := bountyTimeToICS(r.BountyTime)
+			fmt.Println("BEGIN:VEVENT")
+			fmt.Printf("UID:bounty-%s@pollen\n", r.HexID)
+			if start != "" {
+				fmt.Printf("DTSTART:%s\n", start)
+			}
+			fmt.Printf("SUMMARY:Bounty %s at hex %s\n", r.Bounty, r.HexID)
+			fmt.Println("END:VEVENT")
+		}
+		fmt.Println("END:VCALENDAR")
+	default:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(rows)
+	}
+	return ExitSuccess
+}
+
+// bountyTimeToICS converts a bounty_time value to the UTC basic format iCalendar DTSTART
+// expects, returning "" if it can't be parsed so the event is emitted without a start time
+// rather than dropped.
+func bountyTimeToICS(bountyTime string) string {
+	t := parseNullableTime(&bountyTime)
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// mapReportStats is the summary shown alongside the map in report map's HTML output.
+type mapReportStats struct {
+	Region          string
+	HexCount        int
+	CoveredHexCount int
+	FlowerCount     int
+}
+
+// mapReportData is what reportMapTemplate renders: the coverage/flower GeoJSON plus the
+// summary stats, all inlined into the page so it needs no server or database access to view
+// (Leaflet and the base map tiles still load from the network; see reportMapTemplate).
+type mapReportData struct {
+	Stats         mapReportStats
+	HexGeoJSON    template.JS
+	FlowerGeoJSON template.JS
+}
+
+// runReportMap writes a single self-contained HTML file with a Leaflet map of hex coverage and
+// flower locations plus summary stats, optionally scoped to a region (H3HexTop), so it can be
+// emailed to or hosted for a non-technical site host without giving them direct DB access.
+func runReportMap(args []string) int {
+	fs := flag.NewFlagSet("report map", flag.ExitOnError)
+	region := fs.String("region", "", "only include hexes whose region (H3HexTop) matches this value")
+	out := fs.String("out", "report.html", "path to write the HTML report to")
+	fs.Parse(args)
+
+	db, err := openDB(os.Getenv("PG_URL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db connect failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	hexQuery := db.Select("id", "covered", "flower_count", "bounty", "h3_hex_top")
+	if *region != "" {
+		hexQuery = hexQuery.Where("h3_hex_top = ?", *region)
+	}
+	var hexes []Hex
+	if err := hexQuery.Find(&hexes).Error; err != nil {
+		fmt.Fprintf(os.Stderr, "query failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	hexFeatures := make([]geoJSONFeature, 0, len(hexes))
+	coveredCount := 0
+	for _, hex := range hexes {
+		if hex.Covered > 0 {
+			coveredCount++
+		}
+		cell, err := parseCell(hex.ID)
+		if err != nil {
+			continue
+		}
+		ring := make([][2]float64, 0, 7)
+		for _, v := range cell.Boundary() {
+			ring = append(ring, [2]float64{v.Lng, v.Lat})
+		}
+		if len(ring) > 0 {
+			ring = append(ring, ring[0])
+		}
+		hexFeatures = append(hexFeatures, geoJSONFeature{
+			Type:     "Feature",
+			Geometry: geoJSONGeometry{Type: "Polygon", Coordinates: [][][2]float64{ring}},
+			Properties: map[string]interface{}{
+				"id":      hex.ID,
+				"covered": hex.Covered,
+				"bounty":  hex.Bounty,
+			},
+		})
+	}
+
+	flowerQuery := db.Table(tableNameFlower).Select("id", "display_name", "lat", "lng", "h3_hex")
+	if *region != "" {
+		flowerQuery = flowerQuery.Where("h3_hex IN (?)", db.Table(tableNameHex).Select("id").Where("h3_hex_top = ?", *region))
+	}
+	var flowers []Flower
+	if err := flowerQuery.Find(&flowers).Error; err != nil {
+		fmt.Fprintf(os.Stderr, "query failed: %v\n", err)
+		return ExitDBFailure
+	}
+	flowerFeatures := make([]geoJSONFeature, 0, len(flowers))
+	for _, f := range flowers {
+		flowerFeatures = append(flowerFeatures, geoJSONFeature{
+			Type:       "Feature",
+			Geometry:   geoJSONGeometry{Type: "Point", Coordinates: [2]float64{f.Lng, f.Lat}},
+			Properties: map[string]interface{}{"id": f.ID, "display_name": f.DisplayName},
+		})
+	}
+
+	hexJSON, err := json.Marshal(geoJSONFeatureCollection{Type: "FeatureCollection", Features: hexFeatures})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "encoding hexes failed: %v\n", err)
+		return ExitRunFailure
+	}
+	flowerJSON, err := json.Marshal(geoJSONFeatureCollection{Type: "FeatureCollection", Features: flowerFeatures})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "encoding flowers failed: %v\n", err)
+		return ExitRunFailure
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "creating %s failed: %v\n", *out, err)
+		return ExitRunFailure
+	}
+	defer f.Close()
+
+	data := mapReportData{
+		Stats: mapReportStats{
+			Region:          *region,
+			HexCount:        len(hexes),
+			CoveredHexCount: coveredCount,
+			FlowerCount:     len(flowers),
+		},
+		HexGeoJSON:    template.JS(hexJSON),
+		FlowerGeoJSON: template.JS(flowerJSON),
+	}
+	if err := reportMapTemplate.Execute(f, data); err != nil {
+		fmt.Fprintf(os.Stderr, "writing %s failed: %v\n", *out, err)
+		return ExitRunFailure
+	}
+
+	fmt.Printf("wrote %s\n", *out)
+	return ExitSuccess
+}
+
+// reportMapTemplate renders a self-contained coverage map: Leaflet and its tile layer are
+// pulled from a public CDN at view time, but the hex/flower geometry and stats are inlined, so
+// the file needs network access only for Leaflet itself and the base map tiles.
+var reportMapTemplate = template.Must(template.New("map").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Pollen coverage report{{if .Stats.Region}} - {{.Stats.Region}}{{end}}</title>
+<link rel="stylesheet" href="https://unpkg.com/leaflet@1.9.4/dist/leaflet.css">
+<script src="https://unpkg.com/leaflet@1.9.4/dist/leaflet.js"></script>
+<style>
+  body { margin: 0; font-family: sans-serif; }
+  #map { height: 85vh; width: 100%; }
+  #stats { padding: 10px 16px; }
+  #stats span { margin-right: 24px; }
+</style>
+</head>
+<body>
+<div id="stats">
+  <span><strong>Region:</strong> {{if .Stats.Region}}{{.Stats.Region}}{{else}}all{{end}}</span>
+  <span><strong>Hexes:</strong> {{.Stats.HexCount}}</span>
+  <span><strong>Covered:</strong> {{.Stats.CoveredHexCount}}</span>
+  <span><strong>Flowers:</strong> {{.Stats.FlowerCount}}</span>
+</div>
+<div id="map"></div>
+<script>
+  var hexes = {{.HexGeoJSON}};
+  var flowers = {{.FlowerGeoJSON}};
+  var map = L.map('map');
+  L.tileLayer('https://{s}.tile.openstreetmap.org/{z}/{x}/{y}.png', {
+    attribution: '&copy; OpenStreetMap contributors'
+  }).addTo(map);
+
+  var hexLayer = L.geoJSON(hexes, {
+    style: function (feature) {
+      return { color: feature.properties.covered > 0 ? '#2e7d32' : '#c62828', weight: 1, fillOpacity: 0.2 };
+    }
+  }).addTo(map);
+  var flowerLayer = L.geoJSON(flowers, {
+    pointToLayer: function (feature, latlng) {
+      return L.circleMarker(latlng, { radius: 5, color: '#1565c0', fillOpacity: 0.8 })
+        .bindPopup(feature.properties.display_name);
+    }
+  }).addTo(map);
+
+  var bounds = hexLayer.getBounds();
+  if (flowerLayer.getBounds().isValid()) {
+    bounds = bounds.isValid() ? bounds.extend(flowerLayer.getBounds()) : flowerLayer.getBounds();
+  }
+  if (bounds.isValid()) {
+    map.fitBounds(bounds);
+  } else {
+    map.setView([0, 0], 2);
+  }
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RetryQueue persists items that exhausted their in-process retries (see pollenRetries) so
+// transient API flakiness across a hex, flower, or reward fetch self-heals on the next run
+// instead of requiring a manual re-run.
+type RetryQueue struct {
+	Kind      string `gorm:"primaryKey"`
+	Key       string `gorm:"primaryKey"`
+	LastError string
+	Attempts  int
+	FailedAt  time.Time
+}
+
+var tableNameRetryQueue = "pollen_retry_queue"
+
+func (r *RetryQueue) TableName() string {
+	return tableNameRetryQueue
+}
+
+func init() {
+	models = append(models, RetryQueue{})
+}
+
+// enqueueRetry upserts a RetryQueue row for (kind, key), incrementing Attempts so a
+// persistently-failing item's retry count is visible.
+func enqueueRetry(db *gorm.DB, kind, key string, cause error) error {
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "kind"}, {Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"last_error": cause.Error(),
+			"attempts":   gorm.Expr(tableNameRetryQueue + ".attempts + 1"),
+			"failed_at":  time.Now(),
+		}),
+	}).Create(&RetryQueue{
+		Kind:      kind,
+		Key:       key,
+		LastError: cause.Error(),
+		Attempts:  1,
+		FailedAt:  time.Now(),
+	}).Error
+}
+
+// dequeueRetry removes (kind, key) from the retry queue once it succeeds.
+func dequeueRetry(db *gorm.DB, kind, key string) error {
+	return db.Where("kind = ? AND key = ?", kind, key).Delete(&RetryQueue{}).Error
+}
+
+// pendingRetryKeys returns the keys still queued for kind, so a sync step can fold them back
+// into the work it's about to do.
+func pendingRetryKeys(db *gorm.DB, kind string) ([]string, error) {
+	var keys []string
+	err := db.Table(tableNameRetryQueue).Where("kind = ?", kind).Pluck("key", &keys).Error
+	return keys, err
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RewardCheckpoint marks a flower's rewards as synced for the current sweep, so if reward sync
+// crashes partway through thousands of flowers, a restart resumes at the first unfinished one
+// instead of starting over. The table is cleared once a sweep finishes cleanly, so the next
+// day's run still re-syncs everyone (rewards accrue over time, unlike the one-shot sentinels in
+// SyncState).
+type RewardCheckpoint struct {
+	FlowerID    string `gorm:"primaryKey"`
+	CompletedAt time.Time
+}
+
+var tableNameRewardCheckpoint = "pollen_reward_checkpoints"
+
+func (c *RewardCheckpoint) TableName() string {
+	return tableNameRewardCheckpoint
+}
+
+func init() {
+	models = append(models, RewardCheckpoint{})
+}
+
+// isRewardCheckpointed reports whether flowerID's rewards were already synced this sweep.
+func isRewardCheckpointed(db *gorm.DB, flowerID string) (bool, error) {
+	var count int64
+	err := db.Model(&RewardCheckpoint{}).Where("flower_id = ?", flowerID).Count(&count).Error
+	return count > 0, err
+}
+
+// markRewardCheckpointed records flowerID as done for this sweep.
+func markRewardCheckpointed(db *gorm.DB, flowerID string) error {
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&RewardCheckpoint{
+		FlowerID:    flowerID,
+		CompletedAt: time.Now(),
+	}).Error
+}
+
+// clearRewardCheckpoints resets the table once a sweep completes, so the next run starts fresh.
+func clearRewardCheckpoints(db *gorm.DB) error {
+	return db.Exec("DELETE FROM " + tableNameRewardCheckpoint).Error
+}
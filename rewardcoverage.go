@@ -0,0 +1,50 @@
+package main
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RewardCoverage normalizes Reward.Coverage (a text[] of hexes) into one row per
+// (reward_id, hex_id) pair, so "how much was earned covering hex X" is a direct join instead of
+// an array-contains scan over pollen_rewards.
+type RewardCoverage struct {
+	RewardID string `gorm:"primaryKey"`
+	HexID    string `gorm:"primaryKey"`
+}
+
+var tableNameRewardCoverage = "pollen_reward_coverage"
+
+func (c *RewardCoverage) TableName() string {
+	return tableNameRewardCoverage
+}
+
+func init() {
+	models = append(models, RewardCoverage{})
+}
+
+// writeRewardCoverage replaces the coverage rows for rewards, so re-syncing a reward whose
+// coverage has shrunk doesn't leave stale hexes behind. Skipped under --slim, which already
+// drops the source Coverage column as too heavy for tiny databases.
+func writeRewardCoverage(tx *gorm.DB, rewards []Reward) error {
+	if *slim || len(rewards) == 0 {
+		return nil
+	}
+	ids := make([]string, len(rewards))
+	for i, r := range rewards {
+		ids[i] = r.ID
+	}
+	if err := tx.Where("reward_id IN ?", ids).Delete(&RewardCoverage{}).Error; err != nil {
+		return err
+	}
+	var rows []RewardCoverage
+	for _, r := range rewards {
+		for _, hex := range r.Coverage {
+			rows = append(rows, RewardCoverage{RewardID: r.ID, HexID: hex})
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&rows, 200).Error
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errRewardDeadLettered is returned by fetchAndStoreRewards when a flower's reward fetch itself
+// failed and was recorded as a dead letter (see recordRewardFailure) rather than propagated as a
+// hard error. It's a distinct, checkable value rather than plain nil so callers that fingerprint
+// success (syncOneReward, in rewardworkers.go) can tell "handled, try again next run" apart from
+// "actually synced", while callers that only care about not aborting their loop over every other
+// flower (backfill.go) can keep treating it the same way they always have.
+var errRewardDeadLettered = errors.New("reward fetch dead-lettered, see pollen_reward_failures")
+
+// RewardFailure is a dead letter recording that a single flower's rewards couldn't be fetched
+// or decoded, so one malformed payload doesn't abort the rewards phase for every other flower.
+type RewardFailure struct {
+	FlowerID string `gorm:"primaryKey"`
+	Error    string
+	FailedAt time.Time
+}
+
+var tableNameRewardFailure = "pollen_reward_failures"
+
+func (f *RewardFailure) TableName() string {
+	return tableNameRewardFailure
+}
+
+func init() {
+	models = append(models, RewardFailure{})
+}
+
+// recordRewardFailure upserts flowerID's dead letter with err, overwriting any earlier failure
+// so the table always reflects the most recent attempt.
+func recordRewardFailure(db *gorm.DB, flowerID string, err error) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "flower_id"}},
+		UpdateAll: true,
+	}).Create(&RewardFailure{FlowerID: flowerID, Error: err.Error(), FailedAt: time.Now()}).Error
+}
+
+// clearRewardFailure removes flowerID's dead letter once it succeeds on a later attempt.
+func clearRewardFailure(db *gorm.DB, flowerID string) error {
+	return db.Where("flower_id = ?", flowerID).Delete(&RewardFailure{}).Error
+}
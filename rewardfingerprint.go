@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// rewardCandidate is a flower worth considering for reward sync: its ID, and the UpdateTime the
+// Pollen API last reported for it, which rewardSyncNeeded compares against the fingerprint left
+// by the last reward fetch that actually ran.
+type rewardCandidate struct {
+	ID         string
+	UpdateTime string
+}
+
+// RewardSyncFingerprint records the Flower.UpdateTime in effect the last time that flower's
+// reward history was fetched, so syncRewards can skip the call entirely for a device with no new
+// activity since then. Unlike RewardCheckpoint, this is never cleared at the end of a sweep: it
+// is the across-sweep memory that makes the skip possible in the first place.
+type RewardSyncFingerprint struct {
+	FlowerID   string `gorm:"primaryKey"`
+	UpdateTime string
+}
+
+var tableNameRewardSyncFingerprint = "pollen_reward_sync_fingerprints"
+
+func (f *RewardSyncFingerprint) TableName() string {
+	return tableNameRewardSyncFingerprint
+}
+
+func init() {
+	models = append(models, RewardSyncFingerprint{})
+}
+
+// rewardSyncNeeded reports whether flowerID's rewards need fetching: true if it's never been
+// fingerprinted, or updateTime doesn't match the fingerprint left by the last fetch. --force
+// always returns true, the same escape hatch as the flowers/hexes unchanged-since-last-sync
+// skips it mirrors.
+func rewardSyncNeeded(db *gorm.DB, flowerID, updateTime string) (bool, error) {
+	if *force {
+		return true, nil
+	}
+	var stored RewardSyncFingerprint
+	err := db.Where("flower_id = ?", flowerID).First(&stored).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return stored.UpdateTime != updateTime, nil
+}
+
+// markRewardSynced records updateTime as flowerID's latest fingerprinted reward-sync state.
+func markRewardSynced(db *gorm.DB, flowerID, updateTime string) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "flower_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"update_time"}),
+	}).Create(&RewardSyncFingerprint{FlowerID: flowerID, UpdateTime: updateTime}).Error
+}
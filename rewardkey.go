@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// rewardCompositeKey widens the reward primary key from just reward_id to (reward_id, date,
+// device), since in practice the same reward_id has been observed reused across different
+// dates/device types, silently overwriting unrelated rows under the single-column key.
+var rewardCompositeKey = flag.Bool("reward-composite-key", false, "use a composite (reward_id, date, device) primary key for rewards instead of reward_id alone")
+
+// rewardUpsertClause returns the OnConflict clause for writing rewards, targeting the composite
+// key when --reward-composite-key is set and the single-column key otherwise.
+func rewardUpsertClause() clause.OnConflict {
+	columns := []clause.Column{{Name: "id"}}
+	if *rewardCompositeKey {
+		columns = []clause.Column{{Name: "id"}, {Name: "date"}, {Name: "device"}}
+	}
+	return clause.OnConflict{
+		Columns:   columns,
+		UpdateAll: true,
+		DoUpdates: []clause.Assignment{{Column: clause.Column{Name: "updated_at"}, Value: time.Now()}},
+	}
+}
+
+// applyRewardCompositeKey migrates pollen_rewards from its default single-column primary key to
+// the composite one, a no-op once already migrated. It's the migration path for turning
+// --reward-composite-key on against an existing database.
+func applyRewardCompositeKey(db *gorm.DB) error {
+	if !*rewardCompositeKey {
+		return nil
+	}
+	if err := db.Exec("ALTER TABLE " + tableNameReward + " DROP CONSTRAINT IF EXISTS " + tableNameReward + "_pkey").Error; err != nil {
+		return err
+	}
+	return db.Exec("ALTER TABLE " + tableNameReward + " ADD PRIMARY KEY (id, date, device)").Error
+}
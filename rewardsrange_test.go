@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestFilterRewardsByDateRange(t *testing.T) {
+	items := []DeviceRewardItem{{Date: "2023-01-01"}, {Date: "2023-06-15"}, {Date: "2023-12-31"}}
+
+	*rewardsSince, *rewardsUntil = "", ""
+	if got := filterRewardsByDateRange(items); len(got) != 3 {
+		t.Fatalf("no range set: got %d items, want 3", len(got))
+	}
+
+	*rewardsSince, *rewardsUntil = "2023-02-01", "2023-11-01"
+	t.Cleanup(func() { *rewardsSince, *rewardsUntil = "", "" })
+	got := filterRewardsByDateRange(items)
+	if len(got) != 1 || got[0].Date != "2023-06-15" {
+		t.Fatalf("filterRewardsByDateRange = %+v, want only 2023-06-15", got)
+	}
+}
+
+func TestFilterRewardsByDateRangeFallsBackToSince(t *testing.T) {
+	items := []DeviceRewardItem{{Date: "2023-01-01"}, {Date: "2023-06-15"}, {Date: "2023-12-31"}}
+
+	*since = "2023-02-01"
+	t.Cleanup(func() { *since = "" })
+	got := filterRewardsByDateRange(items)
+	if len(got) != 2 {
+		t.Fatalf("--since fallback: got %d items, want 2", len(got))
+	}
+
+	*rewardsSince = "2023-12-01"
+	t.Cleanup(func() { *rewardsSince = "" })
+	got = filterRewardsByDateRange(items)
+	if len(got) != 1 || got[0].Date != "2023-12-31" {
+		t.Fatalf("--rewards-since should take precedence over --since: got %+v", got)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// rewardDateLayout is the date-only format the Pollen API's reward date field has been observed
+// in.
+const rewardDateLayout = "2006-01-02"
+
+// parseRewardDate parses a reward's date field into a time.Time, reusing metrics.timeParseFailures
+// to count unparseable values the same way parseNullableTime does rather than aborting the sync
+// over one malformed record.
+func parseRewardDate(s string) time.Time {
+	t, err := time.Parse(rewardDateLayout, s)
+	if err != nil {
+		atomic.AddInt64(&metrics.timeParseFailures, 1)
+		return time.Time{}
+	}
+	return t
+}
+
+// EarningsByDeviceRange sums PCN earned by device between start and end (inclusive), using the
+// (device, date) index added alongside the Date column switch to a real date type.
+func EarningsByDeviceRange(db *gorm.DB, device string, start, end time.Time) (float64, error) {
+	var total float64
+	err := db.Table(tableNameReward).
+		Where("device = ? AND date >= ? AND date <= ?", device, start, end).
+		Select("COALESCE(SUM(pcn), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// EarningsByWalletRange sums PCN earned by wallet between start and end (inclusive), using the
+// (wallet, date) index added alongside the Date column switch to a real date type.
+func EarningsByWalletRange(db *gorm.DB, wallet string, start, end time.Time) (float64, error) {
+	var total float64
+	err := db.Table(tableNameReward).
+		Where("wallet = ? AND date >= ? AND date <= ?", wallet, start, end).
+		Select("COALESCE(SUM(pcn), 0)").
+		Scan(&total).Error
+	return total, err
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// rewardWorkers controls how many flowers' reward history syncRewards fetches concurrently.
+// Reward sync is the longest phase of a run and is otherwise strictly serial, so raising this
+// past 1 can meaningfully shorten it; every worker shares the single pollenRateLimit limiter, so
+// raising it doesn't increase the rate of outbound Pollen API calls, only how many flowers are
+// in flight waiting on that shared rate.
+var rewardWorkers = flag.Int("reward-workers", 1, "number of flowers to fetch reward history for concurrently")
+
+// syncOneReward checkpoints-and-skips, skips devices with no new activity since the last fetch
+// (see rewardSyncNeeded), fetches, stores, and marks c's reward history checkpointed and
+// fingerprinted: the per-flower unit of work shared by syncRewards' serial and parallel loops.
+func syncOneReward(db *gorm.DB, c rewardCandidate) error {
+	checkpointed, err := isRewardCheckpointed(db, c.ID)
+	if err != nil {
+		return err
+	}
+	if checkpointed {
+		return nil
+	}
+	needed, err := rewardSyncNeeded(db, c.ID, c.UpdateTime)
+	if err != nil {
+		return err
+	}
+	if !needed {
+		return markRewardCheckpointed(db, c.ID)
+	}
+	if err := fetchAndStoreRewards(db, c.ID, true); err != nil {
+		if errors.Is(err, errRewardDeadLettered) {
+			// The fetch itself failed and is already recorded in pollen_reward_failures; leave
+			// the sync fingerprint and checkpoint alone so this flower is retried next run
+			// instead of being skipped forever because its UpdateTime never changes.
+			return nil
+		}
+		return err
+	}
+	if err := markRewardSynced(db, c.ID, c.UpdateTime); err != nil {
+		return err
+	}
+	return markRewardCheckpointed(db, c.ID)
+}
+
+// syncRewardsParallel fans syncOneReward for candidates out across --reward-workers goroutines.
+// Each worker pulls from a shared queue and isolates its own errors: a failure on one flower is
+// logged and counted, not returned immediately, so the other workers keep going instead of one
+// bad device aborting reward sync for everyone still in flight.
+func syncRewardsParallel(db *gorm.DB, candidates []rewardCandidate) error {
+	work := make(chan rewardCandidate, pipelineBufferSize)
+	var wg sync.WaitGroup
+	var done, failed int64
+
+	for w := 0; w < *rewardWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range work {
+				if err := syncOneReward(db, c); err != nil {
+					logProgress("reward sync for %s failed: %v\n", c.ID, err)
+					atomic.AddInt64(&failed, 1)
+					continue
+				}
+				if n := atomic.AddInt64(&done, 1); n%100 == 0 {
+					logProgress("Reward progress: %d/%d\n", n, len(candidates))
+				}
+			}
+		}()
+	}
+
+	for _, c := range candidates {
+		if deadlineExceeded() || requestBudgetExceeded() {
+			break
+		}
+		work <- c
+	}
+	close(work)
+	wg.Wait()
+
+	if failed > 0 {
+		return fmt.Errorf("%d flower(s) failed reward sync, see log above", failed)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package main
+
+// rseViews summarize the RSE ratio (pollen_rewards.rse_ratio) distribution per device and per
+// day, since RSE directly affects payouts and operators want to spot degradation without writing
+// ad-hoc SQL every time. They're materialized so the percentile aggregation isn't recomputed on
+// every dashboard load; refreshMaterializedViews (refreshviews.go) keeps them current, run once
+// at the end of every sync rather than on every read.
+var rseViews = []string{
+	`CREATE MATERIALIZED VIEW IF NOT EXISTS pollen_rse_by_device AS
+		SELECT device,
+			MIN(rse_ratio) AS min_rse_ratio,
+			AVG(rse_ratio) AS avg_rse_ratio,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY rse_ratio) AS p50_rse_ratio,
+			PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY rse_ratio) AS p95_rse_ratio,
+			COUNT(*) AS reward_count
+		FROM pollen_rewards
+		GROUP BY device`,
+	`CREATE MATERIALIZED VIEW IF NOT EXISTS pollen_rse_by_day AS
+		SELECT date,
+			MIN(rse_ratio) AS min_rse_ratio,
+			AVG(rse_ratio) AS avg_rse_ratio,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY rse_ratio) AS p50_rse_ratio,
+			PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY rse_ratio) AS p95_rse_ratio,
+			COUNT(*) AS reward_count
+		FROM pollen_rewards
+		GROUP BY date`,
+}
+
+// materializedViewNames lists every materialized view this package creates, so
+// refreshMaterializedViews doesn't need updating when a new one is added elsewhere.
+var materializedViewNames = []string{
+	"pollen_rse_by_device",
+	"pollen_rse_by_day",
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+func init() {
+	registerSubcommand("schema", runSchemaCommand)
+}
+
+// runSchemaCommand dispatches `schema <subcommand>`.
+func runSchemaCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: schema dump [--format markdown|json]")
+		return ExitRunFailure
+	}
+	switch args[0] {
+	case "dump":
+		return runSchemaDump(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown schema subcommand %q\n", args[0])
+		return ExitRunFailure
+	}
+}
+
+// tableDoc is one table's documentation: its name and every column gorm will migrate for it.
+type tableDoc struct {
+	Table   string      `json:"table"`
+	Columns []columnDoc `json:"columns"`
+}
+
+// columnDoc is one column's name, Go/SQL-ish type, and meaning, the latter pulled from the
+// field's `doc:"..."` struct tag when the model author bothered to write one.
+type columnDoc struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Doc  string `json:"doc,omitempty"`
+}
+
+// docTableNamer is satisfied by every gorm model in models (see main.go's TableName methods).
+type docTableNamer interface {
+	TableName() string
+}
+
+// schemaDocs reflects over every model in models, pairing each field's column name and Go type
+// with its `doc:"..."` tag, so `schema dump` stays correct as models change without needing a
+// second, hand-maintained copy of the schema.
+func schemaDocs() []tableDoc {
+	docs := make([]tableDoc, 0, len(models))
+	for _, model := range models {
+		t := reflect.TypeOf(model)
+		table := t.Name()
+		if named, ok := reflect.New(t).Interface().(docTableNamer); ok {
+			table = named.TableName()
+		}
+		doc := tableDoc{Table: table}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			doc.Columns = append(doc.Columns, columnDoc{
+				Name: gormColumnName(f),
+				Type: f.Type.String(),
+				Doc:  f.Tag.Get("doc"),
+			})
+		}
+		docs = append(docs, doc)
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].Table < docs[j].Table })
+	return docs
+}
+
+// gormColumnName returns f's column name: an explicit `gorm:"column:..."` override if present,
+// otherwise toSnakeCase(f.Name), gorm's own default naming convention.
+func gormColumnName(f reflect.StructField) string {
+	for _, part := range strings.Split(f.Tag.Get("gorm"), ";") {
+		if name, ok := strings.CutPrefix(part, "column:"); ok {
+			return name
+		}
+	}
+	return toSnakeCase(f.Name)
+}
+
+// toSnakeCase approximates gorm's default column-naming convention, converting Go field names
+// like "H3HexTop" to "h3_hex_top" and "RSERatio" to "rse_ratio".
+func toSnakeCase(name string) string {
+	var buf strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prevUpper := unicode.IsUpper(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !prevUpper || nextLower {
+				buf.WriteByte('_')
+			}
+		}
+		buf.WriteRune(unicode.ToLower(r))
+	}
+	return buf.String()
+}
+
+// renderSchemaMarkdown renders docs as one Markdown section per table, a column table per
+// section.
+func renderSchemaMarkdown(docs []tableDoc) string {
+	var b strings.Builder
+	for _, doc := range docs {
+		fmt.Fprintf(&b, "## %s\n\n", doc.Table)
+		fmt.Fprintf(&b, "| Column | Type | Meaning |\n|---|---|---|\n")
+		for _, col := range doc.Columns {
+			meaning := col.Doc
+			if meaning == "" {
+				meaning = "-"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", col.Name, col.Type, meaning)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// runSchemaDump prints the data dictionary for every table this tool manages, generated from
+// the gorm models in main.go's `models` slice rather than hand-maintained separately, so it
+// can't drift out of sync with the schema.
+func runSchemaDump(args []string) int {
+	fs := flag.NewFlagSet("schema dump", flag.ExitOnError)
+	format := fs.String("format", "markdown", "output format: markdown or json")
+	fs.Parse(args)
+
+	docs := schemaDocs()
+	switch *format {
+	case "markdown":
+		fmt.Print(renderSchemaMarkdown(docs))
+	case "json":
+		out, err := json.MarshalIndent(docs, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "encoding schema failed: %v\n", err)
+			return ExitRunFailure
+		}
+		fmt.Println(string(out))
+	default:
+		fmt.Fprintf(os.Stderr, "unknown --format %q, want markdown or json\n", *format)
+		return ExitRunFailure
+	}
+	return ExitSuccess
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// activeSchemaDrift is the package-level recorder pollenAPICall checks every response against,
+// set once in runSync. It's nil (and Check is a safe no-op) for commands that call the Pollen
+// API without a backing run, e.g. --explain.
+var activeSchemaDrift *schemaDriftRecorder
+
+// schemaDriftRecorder persists the last-seen field shape of each Pollen API endpoint and
+// records (and prints) a drift event whenever a fresh response's shape no longer matches it, so
+// an upstream field addition, removal, or retype is caught instead of silently producing zero
+// values or an opaque decode failure downstream.
+type schemaDriftRecorder struct {
+	db *gorm.DB
+}
+
+// SchemaFingerprint is the last-recorded field:type shape for one Pollen API endpoint.
+type SchemaFingerprint struct {
+	Endpoint  string `gorm:"primaryKey"`
+	Shape     string
+	UpdatedAt time.Time
+}
+
+var tableNameSchemaFingerprint = "pollen_schema_fingerprints"
+
+func (f *SchemaFingerprint) TableName() string {
+	return tableNameSchemaFingerprint
+}
+
+// SchemaDriftEvent is an append-only log of every field addition, removal, or retype detected
+// against a stored fingerprint.
+type SchemaDriftEvent struct {
+	ID         uint `gorm:"primaryKey"`
+	Endpoint   string
+	Field      string
+	Kind       string
+	Detail     string
+	DetectedAt time.Time
+}
+
+var tableNameSchemaDriftEvent = "pollen_schema_drift_events"
+
+func (e *SchemaDriftEvent) TableName() string {
+	return tableNameSchemaDriftEvent
+}
+
+func init() {
+	models = append(models, SchemaFingerprint{}, SchemaDriftEvent{})
+}
+
+// endpointName maps a full request URL to a stable logical endpoint name for fingerprinting,
+// stripping the host and query string (which carry per-call parameters like a device or hex id).
+func endpointName(url string) string {
+	name := strings.TrimPrefix(url, pollenAPIBase)
+	if i := strings.Index(name, "?"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// Check fingerprints body's field shape against endpoint's stored fingerprint, recording and
+// logging (via logProgress, so it never lands on a data-producing command's stdout) any drift,
+// then updating the stored fingerprint to the new shape. A nil recorder, an unparseable body, or
+// a response with nothing fingerprintable are all safe no-ops.
+func (r *schemaDriftRecorder) Check(endpoint string, body []byte) {
+	if r == nil {
+		return
+	}
+	fields := responseFields(body)
+	if len(fields) == 0 {
+		return
+	}
+	shape := encodeFields(fields)
+
+	var stored SchemaFingerprint
+	err := r.db.Where("endpoint = ?", endpoint).First(&stored).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logProgress("schema drift check for %s failed: %v\n", endpoint, err)
+			return
+		}
+		if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&SchemaFingerprint{Endpoint: endpoint, Shape: shape, UpdatedAt: time.Now()}).Error; err != nil {
+			logProgress("recording schema fingerprint for %s failed: %v\n", endpoint, err)
+		}
+		return
+	}
+
+	drifts := diffFields(decodeFields(stored.Shape), fields)
+	if len(drifts) == 0 {
+		return
+	}
+	now := time.Now()
+	events := make([]SchemaDriftEvent, len(drifts))
+	for i, d := range drifts {
+		logProgress("schema drift on %s: field %q %s (%s)\n", endpoint, d.Field, d.Kind, d.Detail)
+		events[i] = SchemaDriftEvent{Endpoint: endpoint, Field: d.Field, Kind: d.Kind, Detail: d.Detail, DetectedAt: now}
+	}
+	if err := r.db.CreateInBatches(&events, 200).Error; err != nil {
+		logProgress("recording schema drift for %s failed: %v\n", endpoint, err)
+	}
+	if err := r.db.Model(&stored).Updates(SchemaFingerprint{Shape: shape, UpdatedAt: now}).Error; err != nil {
+		logProgress("updating schema fingerprint for %s failed: %v\n", endpoint, err)
+	}
+}
+
+// fieldDrift is one detected difference between a stored fingerprint and a fresh response.
+type fieldDrift struct {
+	Field  string
+	Kind   string // "added", "removed", or "retyped"
+	Detail string
+}
+
+// diffFields compares previous and current field:type maps, reporting additions, removals, and
+// type changes, sorted by field name for stable output.
+func diffFields(previous, current map[string]string) []fieldDrift {
+	var drifts []fieldDrift
+	for field, typ := range current {
+		prevType, ok := previous[field]
+		if !ok {
+			drifts = append(drifts, fieldDrift{Field: field, Kind: "added", Detail: typ})
+		} else if prevType != typ {
+			drifts = append(drifts, fieldDrift{Field: field, Kind: "retyped", Detail: fmt.Sprintf("%s -> %s", prevType, typ)})
+		}
+	}
+	for field, typ := range previous {
+		if _, ok := current[field]; !ok {
+			drifts = append(drifts, fieldDrift{Field: field, Kind: "removed", Detail: typ})
+		}
+	}
+	sort.Slice(drifts, func(i, j int) bool { return drifts[i].Field < drifts[j].Field })
+	return drifts
+}
+
+// responseFields decodes body's fingerprintable field shape: each field name mapped to its JSON
+// type. Pollen's list endpoints return a bare array of objects, and device-rewards nests its
+// per-day arrays inside an object keyed by date, so this looks for the first array-of-objects up
+// to a few levels deep before falling back to the top-level object itself (hex details).
+func responseFields(body []byte) map[string]string {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil
+	}
+	if fields := firstArrayObjectFields(v, 3); fields != nil {
+		return fields
+	}
+	if obj, ok := v.(map[string]interface{}); ok {
+		return fieldTypes(obj)
+	}
+	return nil
+}
+
+func firstArrayObjectFields(v interface{}, depth int) map[string]string {
+	if depth <= 0 {
+		return nil
+	}
+	switch t := v.(type) {
+	case []interface{}:
+		if len(t) == 0 {
+			return nil
+		}
+		if obj, ok := t[0].(map[string]interface{}); ok {
+			return fieldTypes(obj)
+		}
+		return firstArrayObjectFields(t[0], depth-1)
+	case map[string]interface{}:
+		for _, val := range t {
+			if fields := firstArrayObjectFields(val, depth-1); fields != nil {
+				return fields
+			}
+		}
+	}
+	return nil
+}
+
+func fieldTypes(obj map[string]interface{}) map[string]string {
+	out := make(map[string]string, len(obj))
+	for k, v := range obj {
+		out[k] = jsonTypeName(v)
+	}
+	return out
+}
+
+func jsonTypeName(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "bool"
+	case nil:
+		return "null"
+	default:
+		return "unknown"
+	}
+}
+
+// encodeFields and decodeFields serialize a field-shape map to/from the flat string stored in
+// SchemaFingerprint.Shape, e.g. "field_a:string,field_b:number".
+func encodeFields(fields map[string]string) string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + ":" + fields[k]
+	}
+	return strings.Join(parts, ",")
+}
+
+func decodeFields(shape string) map[string]string {
+	out := map[string]string{}
+	if shape == "" {
+		return out
+	}
+	for _, part := range strings.Split(shape, ",") {
+		kv := strings.SplitN(part, ":", 2)
+		if len(kv) == 2 {
+			out[kv[0]] = kv[1]
+		}
+	}
+	return out
+}
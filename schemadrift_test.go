@@ -0,0 +1,57 @@
+package main
+
+import "testing"
+
+func TestResponseFieldsArray(t *testing.T) {
+	fields := responseFields([]byte(`[{"id":"a","count":1,"active":true}]`))
+	want := map[string]string{"id": "string", "count": "number", "active": "bool"}
+	if len(fields) != len(want) {
+		t.Fatalf("responseFields = %v, want %v", fields, want)
+	}
+	for k, v := range want {
+		if fields[k] != v {
+			t.Fatalf("responseFields[%q] = %q, want %q", k, fields[k], v)
+		}
+	}
+}
+
+func TestResponseFieldsDateKeyedRewards(t *testing.T) {
+	fields := responseFields([]byte(`{"2024-01-01":[{"rewardId":"r1","pcn":1.5}],"2024-01-02":[]}`))
+	if fields["rewardId"] != "string" || fields["pcn"] != "number" {
+		t.Fatalf("responseFields = %v, want rewardId/pcn fields", fields)
+	}
+}
+
+func TestDiffFieldsDetectsAddedRemovedRetyped(t *testing.T) {
+	previous := map[string]string{"a": "string", "b": "number"}
+	current := map[string]string{"a": "number", "c": "string"}
+	drifts := diffFields(previous, current)
+	if len(drifts) != 3 {
+		t.Fatalf("diffFields = %+v, want 3 drifts", drifts)
+	}
+	byField := map[string]fieldDrift{}
+	for _, d := range drifts {
+		byField[d.Field] = d
+	}
+	if byField["a"].Kind != "retyped" {
+		t.Errorf("field a = %+v, want retyped", byField["a"])
+	}
+	if byField["b"].Kind != "removed" {
+		t.Errorf("field b = %+v, want removed", byField["b"])
+	}
+	if byField["c"].Kind != "added" {
+		t.Errorf("field c = %+v, want added", byField["c"])
+	}
+}
+
+func TestEncodeDecodeFieldsRoundTrip(t *testing.T) {
+	fields := map[string]string{"b": "number", "a": "string"}
+	shape := encodeFields(fields)
+	if shape != "a:string,b:number" {
+		t.Fatalf("encodeFields = %q", shape)
+	}
+	got := decodeFields(shape)
+	if len(got) != 2 || got["a"] != "string" || got["b"] != "number" {
+		t.Fatalf("decodeFields(%q) = %v", shape, got)
+	}
+}
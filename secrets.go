@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+)
+
+// secretsProvider and secretsPath let PG_URL and the Pollen API key be fetched from Vault or
+// AWS Secrets Manager at startup instead of living in .env files on the cron host.
+var (
+	secretsProvider = flag.String("secrets-provider", "env", "where to load PG_URL and the Pollen API key from: env, vault, or aws-secretsmanager")
+	secretsPath     = flag.String("secrets-path", "", "Vault KV v2 path or AWS Secrets Manager secret id/ARN to read (required unless --secrets-provider=env)")
+)
+
+// secretFields is the expected shape of the secret payload, regardless of backend: a flat
+// object with pg_url and/or pollen_api_key keys, only overriding what's present.
+type secretFields struct {
+	PGURL        string `json:"pg_url"`
+	PollenAPIKey string `json:"pollen_api_key"`
+}
+
+// loadSecrets applies --secrets-provider, overriding pgURL and the Pollen API key request
+// header in place when the provider returns a value for them. A no-op when --secrets-provider
+// is "env" (the default), since that's already how PG_URL and the API key are configured.
+func loadSecrets(pgURL *string) error {
+	var fields secretFields
+	var err error
+	switch *secretsProvider {
+	case "env":
+		return nil
+	case "vault":
+		fields, err = readVaultSecret(*secretsPath)
+	case "aws-secretsmanager":
+		fields, err = readAWSSecret(*secretsPath)
+	default:
+		return fmt.Errorf("unknown --secrets-provider %q (expected env, vault, or aws-secretsmanager)", *secretsProvider)
+	}
+	if err != nil {
+		return fmt.Errorf("loading secrets from %s: %w", *secretsProvider, err)
+	}
+	if fields.PGURL != "" {
+		*pgURL = fields.PGURL
+	}
+	if fields.PollenAPIKey != "" {
+		pollenAPIHeaders["x-api-key"] = fields.PollenAPIKey
+	}
+	return nil
+}
+
+// readVaultSecret reads a KV v2 secret from Vault using VAULT_ADDR and VAULT_TOKEN, matching
+// the env vars the Vault CLI itself reads.
+func readVaultSecret(path string) (secretFields, error) {
+	if path == "" {
+		return secretFields{}, fmt.Errorf("--secrets-path is required for --secrets-provider=vault")
+	}
+	addr := os.Getenv("VAULT_ADDR")
+	token := os.Getenv("VAULT_TOKEN")
+	if addr == "" || token == "" {
+		return secretFields{}, fmt.Errorf("VAULT_ADDR and VAULT_TOKEN must be set")
+	}
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/v1/%s", addr, path), nil)
+	if err != nil {
+		return secretFields{}, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	cli := &http.Client{Timeout: *httpTimeout}
+	res, err := doHTTP(cli, req)
+	if err != nil {
+		return secretFields{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return secretFields{}, fmt.Errorf("vault returned status %d", res.StatusCode)
+	}
+	var body struct {
+		Data struct {
+			Data secretFields `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return secretFields{}, err
+	}
+	return body.Data.Data, nil
+}
+
+// readAWSSecret reads a secret from AWS Secrets Manager by shelling out to the `aws` CLI,
+// which already handles credential resolution (instance profile, env vars, SSO) the same way
+// every other tool on the host does, rather than pulling in the full AWS SDK for one call.
+func readAWSSecret(secretID string) (secretFields, error) {
+	if secretID == "" {
+		return secretFields{}, fmt.Errorf("--secrets-path is required for --secrets-provider=aws-secretsmanager")
+	}
+	cmd := exec.Command("aws", "secretsmanager", "get-secret-value", "--secret-id", secretID, "--query", "SecretString", "--output", "text")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return secretFields{}, fmt.Errorf("%w: %s", err, out.String())
+	}
+	var fields secretFields
+	if err := json.Unmarshal(out.Bytes(), &fields); err != nil {
+		return secretFields{}, err
+	}
+	return fields, nil
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	registerSubcommand("serve", runServeCommand)
+}
+
+// runServeCommand starts a small read-only JSON API over the synced data, for tools (like a
+// field tech's complaint-triage app) that want live lookups instead of querying Postgres
+// directly.
+func runServeCommand(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":8090", "address to serve the API on")
+	fs.Parse(args)
+
+	db, err := openDB(os.Getenv("PG_URL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db connect failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/flowers/near", func(w http.ResponseWriter, r *http.Request) {
+		handleFlowersNear(w, r, db)
+	})
+	mux.HandleFunc("/tiles/", func(w http.ResponseWriter, r *http.Request) {
+		handleHexTile(w, r, db)
+	})
+	fmt.Printf("serving on %s\n", *addr)
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		fmt.Fprintf(os.Stderr, "serve failed: %v\n", err)
+		return ExitRunFailure
+	}
+	return ExitSuccess
+}
+
+// nearbyFlower is one result from /flowers/near.
+type nearbyFlower struct {
+	ID          string  `json:"id"`
+	DisplayName string  `json:"display_name"`
+	Lat         float64 `json:"lat"`
+	Lng         float64 `json:"lng"`
+	DistanceKM  float64 `json:"distance_km"`
+}
+
+// handleFlowersNear serves GET /flowers/near?lat=&lng=&radius=, returning the flowers with
+// stored lat/lng within radius kilometers, nearest first. radius defaults to 5km if unset.
+func handleFlowersNear(w http.ResponseWriter, r *http.Request, db *gorm.DB) {
+	lat, err := strconv.ParseFloat(r.URL.Query().Get("lat"), 64)
+	if err != nil {
+		http.Error(w, "invalid or missing lat", http.StatusBadRequest)
+		return
+	}
+	lng, err := strconv.ParseFloat(r.URL.Query().Get("lng"), 64)
+	if err != nil {
+		http.Error(w, "invalid or missing lng", http.StatusBadRequest)
+		return
+	}
+	radiusKM := 5.0
+	if raw := r.URL.Query().Get("radius"); raw != "" {
+		radiusKM, err = strconv.ParseFloat(raw, 64)
+		if err != nil {
+			http.Error(w, "invalid radius", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var flowers []Flower
+	if err := db.Table(tableNameFlower).Select("id", "display_name", "lat", "lng").
+		Where("lat <> 0 OR lng <> 0").Find(&flowers).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	results := make([]nearbyFlower, 0, len(flowers))
+	for _, f := range flowers {
+		distance := haversineKM(lat, lng, f.Lat, f.Lng)
+		if distance <= radiusKM {
+			results = append(results, nearbyFlower{ID: f.ID, DisplayName: f.DisplayName, Lat: f.Lat, Lng: f.Lng, DistanceKM: distance})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].DistanceKM < results[j].DistanceKM })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// handleHexTile serves GET /tiles/{z}/{x}/{y}.geojson, a GeoJSON FeatureCollection of the H3
+// hex polygons (see h3.go) whose centerpoint falls within that slippy-map tile's bounding box,
+// so a Leaflet/Mapbox front-end can overlay live coverage straight off this database.
+func handleHexTile(w http.ResponseWriter, r *http.Request, db *gorm.DB) {
+	z, x, y, err := parseTilePath(r.URL.Path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	features, err := hexFeaturesForTile(db, z, x, y)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/geo+json")
+	json.NewEncoder(w).Encode(geoJSONFeatureCollection{Type: "FeatureCollection", Features: features})
+}
+
+// hexFeaturesForTile returns one GeoJSON Polygon feature per hex (see h3.go for the boundary
+// math) whose centerpoint falls within tile z/x/y's bounding box. Shared by the live /tiles/
+// endpoint and the `export tiles` command (see export.go).
+func hexFeaturesForTile(db *gorm.DB, z, x, y int) ([]geoJSONFeature, error) {
+	minLat, minLng, maxLat, maxLng := tileBounds(z, x, y)
+
+	var hexes []Hex
+	if err := db.Select("id", "covered", "flower_count", "bounty", "lat", "lng").
+		Where("lat BETWEEN ? AND ? AND lng BETWEEN ? AND ?", minLat, maxLat, minLng, maxLng).
+		Find(&hexes).Error; err != nil {
+		return nil, err
+	}
+
+	features := make([]geoJSONFeature, 0, len(hexes))
+	for _, hex := range hexes {
+		cell, err := parseCell(hex.ID)
+		if err != nil {
+			continue
+		}
+		ring := make([][2]float64, 0, 7)
+		for _, v := range cell.Boundary() {
+			ring = append(ring, [2]float64{v.Lng, v.Lat})
+		}
+		if len(ring) > 0 {
+			ring = append(ring, ring[0])
+		}
+		features = append(features, geoJSONFeature{
+			Type: "Feature",
+			Geometry: geoJSONGeometry{
+				Type:        "Polygon",
+				Coordinates: [][][2]float64{ring},
+			},
+			Properties: map[string]interface{}{
+				"id":           hex.ID,
+				"covered":      hex.Covered,
+				"flower_count": hex.FlowerCount,
+				"bounty":       hex.Bounty,
+			},
+		})
+	}
+	return features, nil
+}
+
+// flowerFeaturesForTile returns one GeoJSON Point feature per flower whose lat/lng falls within
+// tile z/x/y's bounding box. Used by `export tiles` (see export.go) to bake flower points
+// alongside hex coverage into the tileset.
+func flowerFeaturesForTile(db *gorm.DB, z, x, y int) ([]geoJSONFeature, error) {
+	minLat, minLng, maxLat, maxLng := tileBounds(z, x, y)
+
+	var flowers []Flower
+	if err := db.Table(tableNameFlower).Select("id", "display_name", "lat", "lng").
+		Where("lat BETWEEN ? AND ? AND lng BETWEEN ? AND ?", minLat, maxLat, minLng, maxLng).
+		Find(&flowers).Error; err != nil {
+		return nil, err
+	}
+
+	features := make([]geoJSONFeature, 0, len(flowers))
+	for _, f := range flowers {
+		features = append(features, geoJSONFeature{
+			Type: "Feature",
+			Geometry: geoJSONGeometry{
+				Type:        "Point",
+				Coordinates: [2]float64{f.Lng, f.Lat},
+			},
+			Properties: map[string]interface{}{
+				"id":           f.ID,
+				"display_name": f.DisplayName,
+			},
+		})
+	}
+	return features, nil
+}
+
+// geoJSONFeatureCollection, geoJSONFeature, and geoJSONGeometry are the minimal GeoJSON shapes
+// handleHexTile needs; encoding/json marshals them directly without a third-party GeoJSON lib.
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type string `json:"type"`
+	// Coordinates shape depends on Type: [][][2]float64 rings for "Polygon", [2]float64 for
+	// "Point".
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// parseTilePath parses the {z}/{x}/{y}.geojson suffix of a /tiles/ request path.
+func parseTilePath(path string) (z, x, y int, err error) {
+	path = strings.TrimPrefix(path, "/tiles/")
+	path = strings.TrimSuffix(path, ".geojson")
+	parts := strings.Split(path, "/")
+	if len(parts) != 3 {
+		return 0, 0, 0, fmt.Errorf("expected /tiles/{z}/{x}/{y}.geojson")
+	}
+	z, err = strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid z: %w", err)
+	}
+	x, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid x: %w", err)
+	}
+	y, err = strconv.Atoi(parts[2])
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid y: %w", err)
+	}
+	return z, x, y, nil
+}
+
+// tileBounds returns the (minLat, minLng, maxLat, maxLng) bounding box of a standard slippy-map
+// tile (the same z/x/y scheme as OpenStreetMap/Mapbox).
+func tileBounds(z, x, y int) (minLat, minLng, maxLat, maxLng float64) {
+	n := math.Pow(2, float64(z))
+	lngAt := func(tx int) float64 { return float64(tx)/n*360.0 - 180.0 }
+	latAt := func(ty int) float64 {
+		yFrac := math.Pi - 2*math.Pi*float64(ty)/n
+		return 180.0 / math.Pi * math.Atan(0.5*(math.Exp(yFrac)-math.Exp(-yFrac)))
+	}
+	minLng, maxLng = lngAt(x), lngAt(x+1)
+	maxLat, minLat = latAt(y), latAt(y+1)
+	return minLat, minLng, maxLat, maxLng
+}
+
+// earthRadiusKM is the mean Earth radius used for haversineKM.
+const earthRadiusKM = 6371.0
+
+// haversineKM returns the great-circle distance in kilometers between two lat/lng points.
+func haversineKM(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+	return earthRadiusKM * c
+}
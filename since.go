@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+	"time"
+)
+
+// since is a global cutoff for users standing up a fresh database who don't want years of
+// history pulled in: it lower-bounds reward ingestion the same way --rewards-since does (unless
+// --rewards-since is set explicitly, which takes precedence), and suppresses flower history
+// snapshots for flowers not seen since the cutoff.
+var since = flag.String("since", "", "skip ingesting rewards and flower history dated before this value (YYYY-MM-DD); overridden by --rewards-since if both are set")
+
+// effectiveRewardsSince returns --rewards-since if set, else --since, as the lower bound
+// filterRewardsByDateRange applies.
+func effectiveRewardsSince() string {
+	if *rewardsSince != "" {
+		return *rewardsSince
+	}
+	return *since
+}
+
+// sinceCutoff parses --since into a time.Time for comparing against LastSeen-style timestamps,
+// returning ok=false if --since is unset or unparseable.
+func sinceCutoff() (cutoff time.Time, ok bool) {
+	if *since == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse("2006-01-02", *since)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// slim drops the heavy array/blob columns (raw bee-sighting arrays, covered-hex lists, the
+// bees_seen JSON blob) that tiny free-tier databases can't afford, keeping only the columns
+// most people actually query: id, hex, lat/lng, rewards, and dates.
+var slim = flag.Bool("slim", false, "skip heavy array/blob columns (bee sightings, covered hexes, etc), for tiny free-tier databases")
+
+// slimColumn is one column dropped in --slim mode, named both as its DB column (for the DROP
+// COLUMN) and its Go struct field (for Omit on insert).
+type slimColumn struct {
+	table  string
+	column string
+	field  string
+}
+
+var slimColumns = []slimColumn{
+	{tableNameHex, "flowers", "Flowers"},
+	{tableNameHex, "flowers_contained", "FlowersContained"},
+	{tableNameFlower, "daily_bees_seen", "DailyBeesSeen"},
+	{tableNameFlower, "hbees_seen", "HBeesSeen"},
+	{tableNameFlower, "covered_hexes", "CoveredHexes"},
+	{tableNameFlower, "daily_covered_hexes", "DailyCoveredHexes"},
+	{tableNameFlower, "daily_hbees_seen", "DailyHBeesSeen"},
+	{tableNameFlower, "bees_seen", "BeesSeen"},
+	{tableNameReward, "coverage", "Coverage"},
+}
+
+// applySlimSchema drops the heavy columns for --slim, a no-op once they're already gone.
+func applySlimSchema(db *gorm.DB) error {
+	if !*slim {
+		return nil
+	}
+	for _, c := range slimColumns {
+		if err := db.Exec(fmt.Sprintf("ALTER TABLE %s DROP COLUMN IF EXISTS %s", c.table, c.column)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// slimOmitFields returns the struct fields to Omit on insert/upsert for tableName, so gorm
+// doesn't try to write to a column --slim already dropped.
+func slimOmitFields(tableName string) []string {
+	if !*slim {
+		return nil
+	}
+	var fields []string
+	for _, c := range slimColumns {
+		if c.table == tableName {
+			fields = append(fields, c.field)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// timestampLayouts are the formats first_seen/last_seen have been observed in from the Pollen
+// API, tried in order until one parses.
+var timestampLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+}
+
+// parseNullableTime parses s (the Pollen API's first_seen/last_seen field) into a *time.Time,
+// returning nil for a nil or empty pointer (a flower that's never been seen). An unparseable
+// non-empty value also returns nil rather than aborting the sync over one malformed field on
+// one record; metrics.timeParseFailures counts how often that happens.
+func parseNullableTime(s *string) *time.Time {
+	if s == nil || *s == "" {
+		return nil
+	}
+	if unix, err := strconv.ParseInt(*s, 10, 64); err == nil {
+		t := time.Unix(unix, 0).UTC()
+		return &t
+	}
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, *s); err == nil {
+			return &t
+		}
+	}
+	atomic.AddInt64(&metrics.timeParseFailures, 1)
+	return nil
+}
@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestParseNullableTime(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	if got := parseNullableTime(nil); got != nil {
+		t.Errorf("parseNullableTime(nil) = %v, want nil", got)
+	}
+	if got := parseNullableTime(str("")); got != nil {
+		t.Errorf(`parseNullableTime("") = %v, want nil`, got)
+	}
+	if got := parseNullableTime(str("not-a-time")); got != nil {
+		t.Errorf(`parseNullableTime("not-a-time") = %v, want nil`, got)
+	}
+
+	got := parseNullableTime(str("2023-05-01T12:00:00Z"))
+	if got == nil || got.Year() != 2023 || got.Month() != 5 || got.Day() != 1 {
+		t.Errorf(`parseNullableTime("2023-05-01T12:00:00Z") = %v, want 2023-05-01`, got)
+	}
+
+	got = parseNullableTime(str("1682942400"))
+	if got == nil {
+		t.Errorf("parseNullableTime of a unix timestamp returned nil")
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	registerSubcommand("top", runTopCommand)
+}
+
+// runTopCommand runs an htop-style live monitor: currently-running sync, recent reward totals,
+// and flowers that changed recently, refreshing in place until interrupted.
+func runTopCommand(args []string) int {
+	fs := flag.NewFlagSet("top", flag.ExitOnError)
+	interval := fs.Duration("interval", 2*time.Second, "refresh interval")
+	window := fs.Duration("window", time.Hour, "how far back to look for recent rewards and flower changes")
+	fs.Parse(args)
+
+	db, err := openDB(os.Getenv("PG_URL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db connect failed: %v\n", err)
+		return ExitDBFailure
+	}
+
+	for {
+		snap, err := collectTopSnapshot(db, *window)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "query failed: %v\n", err)
+			return ExitDBFailure
+		}
+		renderTopSnapshot(snap, *window)
+		time.Sleep(*interval)
+	}
+}
+
+// topSnapshot is the state rendered by one `top` refresh.
+type topSnapshot struct {
+	RunningSyncs   []SyncRun
+	RecentRewards  float64
+	RecentCount    int64
+	ChangedFlowers int64
+}
+
+// collectTopSnapshot queries the handful of cheap aggregates `top` displays.
+func collectTopSnapshot(db *gorm.DB, window time.Duration) (topSnapshot, error) {
+	var snap topSnapshot
+	since := time.Now().Add(-window)
+
+	if err := db.Table(tableNameSyncRun).Where("result = ?", "running").Find(&snap.RunningSyncs).Error; err != nil {
+		return snap, err
+	}
+
+	var rewardAgg struct {
+		Total float64
+		Count int64
+	}
+	if err := db.Table(tableNameReward).
+		Select("COALESCE(SUM(pcn), 0) AS total, COUNT(*) AS count").
+		Where("updated_at >= ?", since).
+		Scan(&rewardAgg).Error; err != nil {
+		return snap, err
+	}
+	snap.RecentRewards = rewardAgg.Total
+	snap.RecentCount = rewardAgg.Count
+
+	if err := db.Table(tableNameFlower).Where("updated_at >= ?", since).Count(&snap.ChangedFlowers).Error; err != nil {
+		return snap, err
+	}
+	return snap, nil
+}
+
+// renderTopSnapshot clears the screen and redraws the latest snapshot, the same way htop does.
+func renderTopSnapshot(snap topSnapshot, window time.Duration) {
+	fmt.Print("\033[H\033[2J")
+	fmt.Printf("pollen top - %s\n\n", time.Now().Format(time.RFC3339))
+
+	fmt.Println("Running syncs:")
+	if len(snap.RunningSyncs) == 0 {
+		fmt.Println("  (none)")
+	}
+	for _, r := range snap.RunningSyncs {
+		fmt.Printf("  label=%q started=%s\n", r.Label, r.StartedAt.Format(time.RFC3339))
+	}
+
+	fmt.Printf("\nRewards in last %s: %d records, %.4f PCN\n", window, snap.RecentCount, snap.RecentRewards)
+	fmt.Printf("Flowers changed in last %s: %d\n", window, snap.ChangedFlowers)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Validator tracks a validator-class device (device_type containing "validator") separately
+// from ordinary flowers, accumulating its reward total and activity window across runs. Without
+// this, validator rows are indistinguishable noise mixed into pollen_rewards alongside flowers.
+type Validator struct {
+	DeviceID    string `gorm:"primaryKey"`
+	DeviceType  string
+	TotalReward float64
+	FirstSeen   time.Time
+	LastSeen    time.Time
+}
+
+var tableNameValidator = "pollen_validators"
+
+func (v *Validator) TableName() string {
+	return tableNameValidator
+}
+
+func init() {
+	models = append(models, Validator{})
+}
+
+// isValidatorDevice reports whether deviceType identifies a validator-class device.
+func isValidatorDevice(deviceType string) bool {
+	return strings.Contains(strings.ToLower(deviceType), "validator")
+}
+
+// recordValidators upserts a Validator row for every validator-class device in rewardItems,
+// recomputing TotalReward from scratch by summing pollen_rewards rather than accumulating onto
+// whatever was already stored. rewardItems is a device's entire fetched reward history, not just
+// newly-seen rewards (see getRewards/GetRewards), so accumulating here would double-count
+// everything already stored on every re-sync; recomputing is idempotent no matter how many times
+// the same history is re-fetched, the same way coveragerollups.go recomputes its rollups.
+func recordValidators(tx *gorm.DB, rewardItems []DeviceRewardItem) error {
+	now := time.Now()
+	deviceTypes := map[string]string{}
+	for _, r := range rewardItems {
+		if !isValidatorDevice(r.DeviceType) {
+			continue
+		}
+		deviceTypes[r.Device] = r.DeviceType
+	}
+	for deviceID, deviceType := range deviceTypes {
+		var total float64
+		// reward is stored as the string the API returned it as (see main.go's Reward struct);
+		// cast only the ones that parse as a plain number, the same as the strconv.ParseFloat
+		// fallback-to-0-on-error this replaced.
+		if err := tx.Table(tableNameReward).
+			Where("device = ?", deviceID).
+			Select(`COALESCE(SUM(CASE WHEN reward ~ '^-?[0-9]+(\.[0-9]+)?$' THEN reward::double precision ELSE 0 END), 0)`).
+			Scan(&total).Error; err != nil {
+			return err
+		}
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "device_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"device_type":  deviceType,
+				"total_reward": total,
+				"last_seen":    now,
+			}),
+		}).Create(&Validator{
+			DeviceID:    deviceID,
+			DeviceType:  deviceType,
+			TotalReward: total,
+			FirstSeen:   now,
+			LastSeen:    now,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// watch, watchHexInterval, and watchFullInterval implement a long-running mode that re-syncs
+// hexes with flowers (and flowers/rewards) frequently, while the full hex-group discovery scan
+// only runs occasionally, so active areas stay fresh without constantly re-fetching thousands
+// of empty hexes.
+var (
+	watch             = flag.Bool("watch", false, "run forever instead of exiting: re-sync flowers/rewards/active hexes every --watch-hex-interval, and a full hex-group scan every --watch-full-interval")
+	watchHexInterval  = flag.Duration("watch-hex-interval", time.Minute*15, "in --watch mode, how often to re-sync flowers, rewards, and hexes that already have flowers")
+	watchFullInterval = flag.Duration("watch-full-interval", time.Hour*24, "in --watch mode, how often to do a full hex-group scan to discover newly covered hexes")
+)
+
+// runWatch loops until --max-duration elapses (or forever if unset), syncing flowers/rewards
+// and already-active hexes every --watch-hex-interval, and the full hexGroups scan only every
+// --watch-full-interval.
+func runWatch(db *gorm.DB, hexGroups []string) {
+	var lastFull time.Time
+	for {
+		summary := runSummary{startedAt: time.Now()}
+		summary.record("flowers", syncFlowers(db))
+		summary.record("rewards", syncRewards(db))
+		summary.record("active-hexes", syncActiveHexes(db))
+		if time.Since(lastFull) >= *watchFullInterval {
+			for _, hexGroup := range hexGroups {
+				if err := validateHexGroup(hexGroup); err != nil {
+					summary.record("hexes", err)
+					continue
+				}
+				_, hexErr := syncHexes(db, hexGroup)
+				summary.record("hexes", hexErr)
+			}
+			lastFull = time.Now()
+		}
+		fmt.Println(summary.String())
+		if deadlineExceeded() || requestBudgetExceeded() {
+			return
+		}
+		time.Sleep(*watchHexInterval)
+	}
+}
+
+// syncActiveHexes re-fetches every hex that already has at least one flower, skipping ones
+// whose adaptive backoff interval (see HexActivity) hasn't elapsed yet.
+func syncActiveHexes(db *gorm.DB) error {
+	var hexes []Hex
+	if err := db.Table(tableNameHex).Where("flower_count > 0").Find(&hexes).Error; err != nil {
+		return err
+	}
+	for _, hex := range hexes {
+		if deadlineExceeded() || requestBudgetExceeded() {
+			return nil
+		}
+		due, err := activityDue(db, hex.ID)
+		if err != nil {
+			return err
+		}
+		if !due {
+			continue
+		}
+		if err := upsertHex(db, HexListItem{ID: hex.ID, FlowerCount: hex.FlowerCount, Covered: hex.Covered}); err != nil {
+			return err
+		}
+		var newCount int
+		if err := db.Table(tableNameHex).Select("flower_count").Where("id = ?", hex.ID).Scan(&newCount).Error; err != nil {
+			return err
+		}
+		if err := recordActivity(db, hex.ID, hex.FlowerCount != newCount); err != nil {
+			return err
+		}
+	}
+	return nil
+}